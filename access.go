@@ -0,0 +1,118 @@
+package gofieldselect
+
+import (
+	"context"
+	"reflect"
+	"slices"
+)
+
+type (
+	// FieldPolicy decides if a caller with the given roles can see the field named field, by its selection name,
+	// of the struct type t. See [WithFieldPolicy].
+	FieldPolicy func(ctx context.Context, roles []string, t reflect.Type, field string) bool
+
+	// access holds the caller the fields are selected for, see [WithRoles].
+	access struct {
+		ctx    context.Context //nolint:containedctx // only lives during a single projection
+		roles  []string
+		policy FieldPolicy
+	}
+)
+
+// WithRoles selects only the fields a caller with roles can see, the ones without a `fieldselect:"roles=..."` tag
+// or with one of its roles, in every API taking options, like [ProjectFor] does. ctx is passed to the
+// [FieldPolicy].
+func WithRoles(ctx context.Context, roles ...string) Option {
+	return func(o *options) {
+		o.access = &access{ctx: ctx, roles: roles}
+	}
+}
+
+// WithFieldPolicy checks with policy, besides the `fieldselect:"roles=..."` tag, if the caller can see each field.
+// Without [WithRoles] or [ProjectFor] the caller has no roles and policy receives [context.Background].
+func WithFieldPolicy(policy FieldPolicy) Option {
+	return func(o *options) {
+		o.policy = policy
+	}
+}
+
+// ProjectFor creates a new instance of [T] with only the fields specified in [n] that a caller with [roles] can
+// see, like [GetWithReflection] does, and returns the paths of the requested fields that were denied.
+// A field with the `fieldselect:"roles=admin,owner"` tag is only visible to the callers with one of those roles,
+// and the [WithFieldPolicy] option can deny more fields.
+// The fields not visible are neither selected by name nor through a wildcard. The caller given here replaces the one
+// of [WithRoles].
+func ProjectFor[T any](ctx context.Context, roles []string, n Node, v T, opts ...Option) (T, []string, error) {
+	var zero T
+
+	if err := ctx.Err(); err != nil {
+		return zero, nil, err
+	}
+
+	o := newOptions(opts)
+	o.access = &access{ctx: ctx, roles: roles, policy: o.policy}
+
+	selected, err := getWithReflection(n, v, o)
+	if err != nil {
+		return zero, nil, err
+	}
+
+	return selected, o.deniedFields(n, reflect.TypeFor[T](), ""), nil
+}
+
+// allowed reports whether the caller can see the field of the struct type t, always true without a caller.
+func (o options) allowed(t reflect.Type, fp fieldPlan) bool {
+	if o.access == nil {
+		return true
+	}
+
+	if len(fp.tag.roles) > 0 && !slices.ContainsFunc(fp.tag.roles, func(role string) bool {
+		return slices.Contains(o.access.roles, role)
+	}) {
+		return false
+	}
+
+	return o.access.policy == nil || o.access.policy(o.access.ctx, o.access.roles, t, fp.name)
+}
+
+// deniedFields returns the paths of the fields named in node, for the type t located in path, that the caller
+// can't see.
+//
+//nolint:exhaustive // the rest of kinds don't have fields
+func (o options) deniedFields(node Node, t reflect.Type, path string) []string {
+	identifiers, ok := node.(Identifiers)
+	if !ok {
+		return nil
+	}
+
+	t = elemType(t)
+
+	var denied []string
+
+	switch t.Kind() {
+	case reflect.Struct:
+		tp := o.planFor(t)
+
+		for _, ident := range identifiers {
+			fp, found := o.field(tp, ident.Value)
+			if !found || fp.tag.mode == modeNever {
+				continue
+			}
+
+			fieldPath := joinPath(path, ident.Value)
+			if !o.allowed(t, fp) {
+				denied = append(denied, fieldPath)
+
+				continue
+			}
+
+			denied = append(denied, o.deniedFields(childNode(ident), fp.typ, fieldPath)...)
+		}
+	case reflect.Map:
+		for _, ident := range identifiers {
+			denied = append(denied, o.deniedFields(childNode(ident), t.Elem(), joinPath(path, ident.Value))...)
+		}
+	}
+
+	return denied
+}
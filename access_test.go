@@ -0,0 +1,177 @@
+package gofieldselect
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type member struct {
+	ID      int     `json:"id"`
+	Name    string  `json:"name"`
+	Email   string  `json:"email"   fieldselect:"roles=admin,owner"`
+	Salary  int     `json:"salary"  fieldselect:"roles=admin"`
+	Manager *member `json:"manager"`
+}
+
+func newMember() member {
+	return member{
+		ID:      1,
+		Name:    "John",
+		Email:   "john@doe.com",
+		Salary:  100,
+		Manager: &member{ID: 2, Name: "Jane", Email: "jane@doe.com", Salary: 200},
+	}
+}
+
+func TestProjectForWildcard(t *testing.T) {
+	t.Parallel()
+
+	got, denied, err := ProjectFor(t.Context(), []string{"owner"}, parse(t, ""), newMember())
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	if len(denied) != 0 {
+		t.Fatalf("expected nothing denied with a wildcard, got %v", denied)
+	}
+
+	if got.Name != "John" || got.Email != "john@doe.com" || got.Salary != 0 {
+		t.Fatalf("expected the fields visible to the owner, got %+v", got)
+	}
+
+	if got.Manager.Email != "jane@doe.com" || got.Manager.Salary != 0 {
+		t.Fatalf("expected the manager fields visible to the owner, got %+v", got.Manager)
+	}
+}
+
+func TestProjectForDenied(t *testing.T) {
+	t.Parallel()
+
+	n := parse(t, "name,email,salary,manager(salary,name)")
+
+	got, denied, err := ProjectFor(t.Context(), nil, n, newMember())
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	if expected := []string{"email", "salary", "manager.salary"}; !slices.Equal(denied, expected) {
+		t.Fatalf("expected denied %v, got %v", expected, denied)
+	}
+
+	if got.Name != "John" || got.Email != "" || got.Salary != 0 || got.Manager.Name != "Jane" || got.Manager.Salary != 0 {
+		t.Fatalf("expected only the public fields, got %+v %+v", got, got.Manager)
+	}
+
+	got, denied, err = ProjectFor(t.Context(), []string{"admin"}, n, newMember())
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	if len(denied) != 0 || got.Salary != 100 || got.Manager.Salary != 200 {
+		t.Fatalf("expected every field for the admin, got %+v and denied %v", got, denied)
+	}
+}
+
+func TestProjectForPolicy(t *testing.T) {
+	t.Parallel()
+
+	policy := func(_ context.Context, roles []string, typ reflect.Type, field string) bool {
+		return typ != reflect.TypeFor[member]() || field != "id" || slices.Contains(roles, "admin")
+	}
+
+	got, denied, err := ProjectFor(t.Context(), []string{"owner"}, parse(t, "id,name"), newMember(),
+		WithFieldPolicy(policy))
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	if !slices.Equal(denied, []string{"id"}) || got.ID != 0 || got.Name != "John" {
+		t.Fatalf("expected id denied by the policy, got %+v and denied %v", got, denied)
+	}
+}
+
+func TestProjectForCanceledContext(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	if _, _, err := ProjectFor(ctx, nil, parse(t, ""), newMember()); err == nil {
+		t.Fatalf("expected an error with a canceled context")
+	}
+}
+
+func TestWithRolesAcrossAPIs(t *testing.T) {
+	t.Parallel()
+
+	jsonOf := func(v any, err error) ([]byte, error) {
+		if err != nil {
+			return nil, err
+		}
+
+		return json.Marshal(v)
+	}
+
+	apis := map[string]func(opts ...Option) ([]byte, error){
+		"GetWithReflection": func(opts ...Option) ([]byte, error) {
+			return jsonOf(GetWithReflection(AllIdentifiers{}, newMember(), opts...))
+		},
+		"Compile": func(opts ...Option) ([]byte, error) {
+			project, err := Compile[member](AllIdentifiers{}, opts...)
+			if err != nil {
+				return nil, err
+			}
+
+			return json.Marshal(project(newMember()))
+		},
+		"Marshal":    func(opts ...Option) ([]byte, error) { return Marshal(AllIdentifiers{}, newMember(), opts...) },
+		"MarshalXML": func(opts ...Option) ([]byte, error) { return MarshalXML(AllIdentifiers{}, newMember(), opts...) },
+		"ToMap":      func(opts ...Option) ([]byte, error) { return jsonOf(ToMap(AllIdentifiers{}, newMember(), opts...)) },
+		"Apply": func(opts ...Option) ([]byte, error) {
+			m := newMember()
+
+			return jsonOf(m, Apply(AllIdentifiers{}, &m, opts...))
+		},
+		"Project": func(opts ...Option) ([]byte, error) {
+			return jsonOf(Project[member, member](AllIdentifiers{}, newMember(), opts...))
+		},
+		"WriteCSV": func(opts ...Option) ([]byte, error) {
+			var sb strings.Builder
+			err := WriteCSV(&sb, AllIdentifiers{}, []member{newMember()}, opts...)
+
+			return []byte(sb.String()), err
+		},
+	}
+
+	for name, api := range apis {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := api(WithRoles(t.Context(), "owner"))
+			if err != nil {
+				t.Fatalf("error: %v", err)
+			}
+
+			if !bytes.Contains(got, []byte("john@doe.com")) || bytes.Contains(got, []byte("100")) ||
+				bytes.Contains(got, []byte("200")) {
+				t.Fatalf("expected the fields visible to the owner, got %s", got)
+			}
+
+			got, err = api(WithFieldPolicy(func(_ context.Context, roles []string, _ reflect.Type, field string) bool {
+				return len(roles) == 0 && field != "name" && field != "Name"
+			}))
+			if err != nil {
+				t.Fatalf("error: %v", err)
+			}
+
+			if bytes.Contains(got, []byte("John")) || bytes.Contains(got, []byte("@doe.com")) {
+				t.Fatalf("expected the public fields allowed by the policy, got %s", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package gofieldselect
+
+import (
+	"reflect"
+)
+
+// applier zeroes in place the parts of values not selected.
+type applier struct {
+	opts options
+	// visiting holds the pointers being applied with a wildcard selection, to detect cycles.
+	visiting map[visitKey]struct{}
+}
+
+// Apply zeroes in place every field of [dst] not specified in [n], instead of allocating a new instance
+// like [GetWithReflection] does.
+// It goes through nested structs, pointers, slices and maps, so the values they point to are modified too.
+// [T] must be a struct or a pointer to a struct, unexported fields are left untouched.
+func Apply[T any](n Node, dst *T, opts ...Option) error {
+	rt := reflect.TypeFor[T]()
+	if !isStructOrPtrToStruct(rt) {
+		return NewTypeNotValidError(rt.Kind())
+	}
+
+	if dst == nil {
+		return nil
+	}
+
+	a := applier{opts: newOptions(opts)}
+
+	return a.applyValue(n, reflect.ValueOf(dst).Elem())
+}
+
+// applyValue zeroes the parts of v not specified in node, v must be settable.
+//
+//nolint:exhaustive // the rest of kinds don't have children
+func (a *applier) applyValue(node Node, v reflect.Value) error {
+	all := isAllIdentifiers(node)
+	if all && (isLeafType(v.Type()) || !a.opts.restricted(v.Type())) {
+		return nil
+	}
+
+	if isLeafType(v.Type()) {
+		return ErrChildSelectionOnLeaf
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return a.applyStruct(node, v)
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+
+		if all {
+			key := visitKey{ptr: v.Pointer(), typ: v.Type()}
+			if _, ok := a.visiting[key]; ok {
+				// already being applied
+				return nil
+			}
+
+			if a.visiting == nil {
+				a.visiting = make(map[visitKey]struct{})
+			}
+
+			a.visiting[key] = struct{}{}
+			defer delete(a.visiting, key)
+		}
+
+		return a.applyValue(node, v.Elem())
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			if err := a.applyValue(node, v.Index(i)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case reflect.Map:
+		return a.applyMap(node, v)
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+
+		// the value held by an interface is not addressable
+		elem := reflect.New(v.Elem().Type()).Elem()
+		elem.Set(v.Elem())
+
+		if err := a.applyValue(node, elem); err != nil {
+			return err
+		}
+
+		v.Set(elem)
+
+		return nil
+	default:
+		return nil
+	}
+}
+
+func (a *applier) applyStruct(node Node, v reflect.Value) error {
+	t := v.Type()
+	all := isAllIdentifiers(node)
+
+	for _, fp := range a.opts.planFor(t).fields {
+		fv, exists := fieldByIndex(v, fp.index)
+		if !exists {
+			continue
+		}
+
+		child, ok := node, true
+		if fp.ignored {
+			// ignored by JSON, so it's only kept with the rest of the struct
+			ok = all && !fp.tag.restricted() && a.opts.allowed(t, fp)
+		} else {
+			child, ok = a.opts.selectedField(node, t, fp)
+		}
+
+		if !ok {
+			fv.SetZero()
+
+			continue
+		}
+
+		if err := a.applyValue(child, fv); err != nil {
+			return wrapFieldError(fp.name, err)
+		}
+	}
+
+	return nil
+}
+
+// applyMap removes the entries of a map with string keys that are not selected, and applies the selection
+// to the values otherwise.
+func (a *applier) applyMap(node Node, v reflect.Value) error {
+	stringKeys := v.Type().Key().Kind() == reflect.String
+
+	iter := v.MapRange()
+	for iter.Next() {
+		child := node
+
+		if stringKeys {
+			ident, ok := a.opts.selectField(node, iter.Key().String())
+			if !ok {
+				v.SetMapIndex(iter.Key(), reflect.Value{})
+
+				continue
+			}
+
+			child = childNode(ident)
+		}
+
+		if isAllIdentifiers(child) && !a.opts.restricted(v.Type().Elem()) {
+			continue
+		}
+
+		// map values are not addressable
+		elem := reflect.New(v.Type().Elem()).Elem()
+		elem.Set(iter.Value())
+
+		if err := a.applyValue(child, elem); err != nil {
+			if stringKeys {
+				return wrapFieldError(iter.Key().String(), err)
+			}
+
+			return err
+		}
+
+		v.SetMapIndex(iter.Key(), elem)
+	}
+
+	return nil
+}
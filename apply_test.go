@@ -0,0 +1,90 @@
+package gofieldselect
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestApply(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		selection string
+		expected  Team
+	}{
+		"all fields": {
+			selection: "",
+			expected:  newTeam(),
+		},
+		"root field": {
+			selection: "name",
+			expected:  Team{Name: "core"},
+		},
+		"nested pointer": {
+			selection: "leader(address(street))",
+			expected:  Team{Leader: &UserPtr{Address: &Address{Street: "Main"}}},
+		},
+		"slice elements": {
+			selection: "members(name)",
+			expected:  Team{Members: []User{{Name: "Jane"}}},
+		},
+		"map keys": {
+			selection: "labels(env),offices(hq(number))",
+			expected: Team{
+				Labels:  map[string]string{"env": "prod"},
+				Offices: map[string]Address{"hq": {Number: 3}},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			team := newTeam()
+			if err := Apply(parse(t, tc.selection), &team); err != nil {
+				t.Fatalf("error: %v", err)
+			}
+
+			if !reflect.DeepEqual(team, tc.expected) {
+				t.Fatalf("expected %+v; got %+v", tc.expected, team)
+			}
+		})
+	}
+}
+
+func TestApplyPointer(t *testing.T) {
+	t.Parallel()
+
+	u := &User{Name: "John", Password: "secret", Address: Address{Street: "Main"}}
+	if err := Apply(parse(t, "name"), &u); err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	if *u != (User{Name: "John"}) {
+		t.Fatalf("expected only name; got %+v", u)
+	}
+}
+
+func TestApplyChildSelectionOnLeaf(t *testing.T) {
+	t.Parallel()
+
+	e := Event{CreatedAt: time.Now()}
+
+	err := Apply(parse(t, "createdAt(foo)"), &e)
+	if !errors.Is(err, ErrChildSelectionOnLeaf) {
+		t.Fatalf("expected ErrChildSelectionOnLeaf; got %v", err)
+	}
+}
+
+func newTeam() Team {
+	return Team{
+		Name:    "core",
+		Leader:  &UserPtr{Name: "John", Age: 30, Address: &Address{Street: "Main", Number: 1}},
+		Members: []User{{Name: "Jane", Age: 20, Address: Address{Street: "Second", Number: 2}}},
+		Labels:  map[string]string{"env": "prod", "tier": "1"},
+		Offices: map[string]Address{"hq": {Street: "Third", Number: 3}},
+	}
+}
@@ -0,0 +1,301 @@
+package gofieldselect
+
+import (
+	"reflect"
+	"sync"
+)
+
+type (
+	// Projector returns a copy of the value with only the fields of the compiled selection, see [Compile].
+	// It is safe for concurrent use.
+	Projector[T any] func(T) T
+
+	// ProjectorCache caches the projectors of [T] by their selection string, e.g. the `?fields=` query parameter.
+	// It is safe for concurrent use.
+	ProjectorCache[T any] struct {
+		mu         sync.Mutex
+		size       int
+		opts       []Option
+		projectors map[string]Projector[T]
+		// order keeps the selections in insertion order, to evict the oldest one when the cache is full.
+		order []string
+	}
+
+	// program sets in dst the value of src with the selection it was compiled for.
+	program func(src, dst reflect.Value)
+
+	compiledField struct {
+		index []int
+		run   program
+	}
+)
+
+// Compile resolves once the selection [n] against [T], which must be a struct or a pointer to a struct,
+// and returns a [Projector] that behaves like [GetWithReflection] without looking up fields or parsing tags
+// on each call.
+// The selection is validated with [Validate], and the options are the ones of [GetWithReflection].
+func Compile[T any](n Node, opts ...Option) (Projector[T], error) {
+	rt := reflect.TypeFor[T]()
+	if !isStructOrPtrToStruct(rt) {
+		return nil, NewTypeNotValidError(rt.Kind())
+	}
+
+	if err := Validate[T](n, opts...); err != nil {
+		return nil, err
+	}
+
+	o := newOptions(opts)
+
+	var run program
+	if o.deepCopy {
+		// every reference is duplicated on each call
+		run = o.copyProgram(n)
+	} else {
+		run = o.compileValue(n, rt)
+	}
+
+	all := isAllIdentifiers(n)
+
+	return func(source T) T {
+		src := reflect.ValueOf(&source).Elem()
+		if rt.Kind() == reflect.Ptr && src.IsNil() {
+			if all {
+				//nolint:errcheck // it's always T
+				return reflect.New(rt.Elem()).Interface().(T)
+			}
+
+			var zero T
+
+			return zero
+		}
+
+		var selected T
+
+		run(src, reflect.ValueOf(&selected).Elem())
+
+		return selected
+	}, nil
+}
+
+// NewProjectorCache creates a [ProjectorCache] that keeps at most size projectors, evicting the oldest one when
+// it's full. A size of zero or less disables the cache, so every selection is parsed and compiled on each call.
+// The projectors are compiled with opts, see [Compile].
+func NewProjectorCache[T any](size int, opts ...Option) *ProjectorCache[T] {
+	return &ProjectorCache[T]{
+		size:       size,
+		opts:       opts,
+		projectors: make(map[string]Projector[T], max(size, 0)),
+	}
+}
+
+// Get returns the projector for the selection, parsing and compiling it the first time.
+func (pc *ProjectorCache[T]) Get(fieldSelection string) (Projector[T], error) {
+	pc.mu.Lock()
+	p, ok := pc.projectors[fieldSelection]
+	pc.mu.Unlock()
+
+	if ok {
+		return p, nil
+	}
+
+	n, err := Parse(fieldSelection)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err = Compile[T](n, pc.opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if pc.size <= 0 {
+		return p, nil
+	}
+
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	if _, ok = pc.projectors[fieldSelection]; !ok {
+		if len(pc.order) >= pc.size {
+			delete(pc.projectors, pc.order[0])
+			pc.order = pc.order[1:]
+		}
+
+		pc.projectors[fieldSelection] = p
+		pc.order = append(pc.order, fieldSelection)
+	}
+
+	return p, nil
+}
+
+// compileValue returns the program that copies a value of type t with the selection node.
+// It mirrors the behavior of the copier without deep copy.
+//
+//nolint:exhaustive // the rest of kinds are copied as they are
+func (o options) compileValue(node Node, t reflect.Type) program {
+	if isLeafType(t) {
+		return setValue
+	}
+
+	if isAllIdentifiers(node) && o.restricted(t) {
+		// the restricted fields are removed at runtime, a wildcard can reach recursive types
+		return o.copyProgram(AllIdentifiers{})
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return o.compileStruct(node, t)
+	case reflect.Ptr:
+		if t.Elem().Kind() != reflect.Struct {
+			return setValue
+		}
+
+		elemType := t.Elem()
+		elem := o.compileValue(node, elemType)
+
+		return func(src, dst reflect.Value) {
+			if src.IsNil() {
+				dst.SetZero()
+
+				return
+			}
+
+			ptr := reflect.New(elemType)
+			elem(src.Elem(), ptr.Elem())
+			dst.Set(ptr)
+		}
+	case reflect.Slice:
+		if isAllIdentifiers(node) {
+			return setValue
+		}
+
+		elem := o.compileValue(node, t.Elem())
+
+		return func(src, dst reflect.Value) {
+			if src.IsNil() {
+				dst.SetZero()
+
+				return
+			}
+
+			dst.Set(reflect.MakeSlice(t, src.Len(), src.Len()))
+
+			for i := range src.Len() {
+				elem(src.Index(i), dst.Index(i))
+			}
+		}
+	case reflect.Array:
+		if isAllIdentifiers(node) {
+			return setValue
+		}
+
+		elem := o.compileValue(node, t.Elem())
+
+		return func(src, dst reflect.Value) {
+			for i := range src.Len() {
+				elem(src.Index(i), dst.Index(i))
+			}
+		}
+	case reflect.Map:
+		return o.compileMap(node, t)
+	default:
+		return setValue
+	}
+}
+
+func (o options) compileStruct(node Node, t reflect.Type) program {
+	if isAllIdentifiers(node) {
+		return setValue
+	}
+
+	var fields []compiledField
+
+	for _, fp := range o.planFor(t).fields {
+		if fp.ignored {
+			continue
+		}
+
+		child, ok := o.selectedField(node, t, fp)
+		if !ok {
+			continue
+		}
+
+		fields = append(fields, compiledField{
+			index: fp.index,
+			run:   o.compileValue(child, fp.typ),
+		})
+	}
+
+	return func(src, dst reflect.Value) {
+		for _, f := range fields {
+			if len(f.index) == 1 {
+				f.run(src.Field(f.index[0]), dst.Field(f.index[0]))
+
+				continue
+			}
+
+			if sf, ok := fieldByIndex(src, f.index); ok {
+				f.run(sf, settableFieldByIndex(dst, f.index))
+			}
+		}
+	}
+}
+
+// compileMap returns the program of a map, only maps with string keys can have a child selection.
+func (o options) compileMap(node Node, t reflect.Type) program {
+	identifiers, ok := node.(Identifiers)
+	if !ok || t.Key().Kind() != reflect.String {
+		return setValue
+	}
+
+	if o.caseInsensitive {
+		// the keys are only known at runtime
+		return o.copyProgram(node)
+	}
+
+	keys := make([]reflect.Value, len(identifiers))
+	values := make([]program, len(identifiers))
+
+	for i, ident := range identifiers {
+		keys[i] = reflect.ValueOf(ident.Value).Convert(t.Key())
+		values[i] = o.compileValue(childNode(ident), t.Elem())
+	}
+
+	return func(src, dst reflect.Value) {
+		if src.IsNil() {
+			dst.SetZero()
+
+			return
+		}
+
+		m := reflect.MakeMapWithSize(t, len(keys))
+
+		for i, key := range keys {
+			v := src.MapIndex(key)
+			if !v.IsValid() {
+				continue
+			}
+
+			elem := reflect.New(t.Elem()).Elem()
+			values[i](v, elem)
+			m.SetMapIndex(key, elem)
+		}
+
+		dst.Set(m)
+	}
+}
+
+// copyProgram returns the program that copies a value with the copier, for the selections resolved at runtime,
+// like a wildcard reaching `explicit` or `never` fields, or deep copies.
+func (o options) copyProgram(node Node) program {
+	return func(src, dst reflect.Value) {
+		c := copier{opts: o}
+
+		// the selection was validated, so it never fails
+		_ = c.copyValue(node, src, dst)
+	}
+}
+
+func setValue(src, dst reflect.Value) {
+	dst.Set(src)
+}
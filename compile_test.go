@@ -0,0 +1,141 @@
+package gofieldselect
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+type Team struct {
+	Name    string             `json:"name"`
+	Leader  *UserPtr           `json:"leader"`
+	Members []User             `json:"members"`
+	Labels  map[string]string  `json:"labels"`
+	Offices map[string]Address `json:"offices"`
+}
+
+func TestCompileMatchesGetWithReflection(t *testing.T) {
+	t.Parallel()
+
+	src := newTeam()
+
+	selections := []string{
+		"",
+		"name",
+		"leader(name,address(street))",
+		"members(name,address(number))",
+		"labels(env),offices(hq(street))",
+	}
+
+	for _, selection := range selections {
+		t.Run(selection, func(t *testing.T) {
+			t.Parallel()
+
+			n := parse(t, selection)
+
+			project, err := Compile[Team](n)
+			if err != nil {
+				t.Fatalf("error: %v", err)
+			}
+
+			expected, err := GetWithReflection(n, src)
+			if err != nil {
+				t.Fatalf("error: %v", err)
+			}
+
+			if got := project(src); !reflect.DeepEqual(got, expected) {
+				t.Fatalf("expected %+v; got %+v", expected, got)
+			}
+		})
+	}
+}
+
+func TestCompileNilPointer(t *testing.T) {
+	t.Parallel()
+
+	project, err := Compile[*User](parse(t, "name"))
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	if got := project(nil); got != nil {
+		t.Fatalf("expected nil; got %+v", got)
+	}
+}
+
+func TestCompileInvalidSelection(t *testing.T) {
+	t.Parallel()
+
+	_, err := Compile[User](parse(t, "name,foo"))
+	if !errors.Is(err, ErrUnknownField) {
+		t.Fatalf("expected ErrUnknownField; got %v", err)
+	}
+
+	_, err = Compile[int](parse(t, "name"))
+	if !errors.As(err, &TypeNotValidError{}) {
+		t.Fatalf("expected TypeNotValidError; got %v", err)
+	}
+}
+
+func TestProjectorCache(t *testing.T) {
+	t.Parallel()
+
+	pc := NewProjectorCache[User](1)
+	src := User{Name: "John", Surname: "Doe"}
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			project, err := pc.Get("name")
+			if err != nil {
+				t.Errorf("error: %v", err)
+
+				return
+			}
+
+			if got := project(src); got != (User{Name: "John"}) {
+				t.Errorf("unexpected projection %+v", got)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if _, err := pc.Get("surname"); err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	if len(pc.projectors) != 1 || pc.projectors["surname"] == nil {
+		t.Fatalf("expected only the last selection to be cached; got %v", pc.order)
+	}
+
+	if _, err := pc.Get("name("); err == nil {
+		t.Fatalf("expected parsing error")
+	}
+}
+
+func TestProjectorCacheDisabled(t *testing.T) {
+	t.Parallel()
+
+	for _, size := range []int{0, -1} {
+		pc := NewProjectorCache[User](size)
+
+		project, err := pc.Get("name")
+		if err != nil {
+			t.Fatalf("error: %v", err)
+		}
+
+		if got := project(User{Name: "John", Surname: "Doe"}); got != (User{Name: "John"}) {
+			t.Fatalf("unexpected projection %+v", got)
+		}
+
+		if len(pc.projectors) != 0 || len(pc.order) != 0 {
+			t.Fatalf("size %d: expected nothing to be cached; got %v", size, pc.order)
+		}
+	}
+}
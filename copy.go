@@ -0,0 +1,302 @@
+package gofieldselect
+
+import (
+	"reflect"
+)
+
+type (
+	// copier copies values applying a selection, sharing or duplicating references depending on the options.
+	copier struct {
+		opts options
+		// visited holds the copies of the pointers, maps and slices already reached with a wildcard selection,
+		// to break cycles.
+		visited map[visitKey]reflect.Value
+	}
+
+	// visitKey identifies a pointer, map or slice, the length tells apart the slices sharing their first element.
+	visitKey struct {
+		ptr uintptr
+		typ reflect.Type
+		len int
+	}
+)
+
+// copyValue sets in dst the value of src with only the fields specified in node.
+// Both values must be of the same type and dst must be settable.
+//
+//nolint:exhaustive // the rest of kinds are copied as they are
+func (c *copier) copyValue(node Node, src, dst reflect.Value) error {
+	if isLeafType(src.Type()) {
+		return c.copyLeaf(node, src, dst)
+	}
+
+	switch src.Kind() {
+	case reflect.Struct:
+		return c.copyStruct(node, src, dst)
+	case reflect.Ptr:
+		return c.copyPtr(node, src, dst)
+	case reflect.Slice:
+		if src.IsNil() || c.shares(node, src.Type()) {
+			dst.Set(src)
+
+			return nil
+		}
+
+		if c.reuse(node, src, dst) {
+			return nil
+		}
+
+		s := reflect.MakeSlice(src.Type(), src.Len(), src.Len())
+		dst.Set(s)
+		c.visit(node, src, s)
+
+		return c.copyElements(node, src, dst)
+	case reflect.Array:
+		if c.shares(node, src.Type()) {
+			dst.Set(src)
+
+			return nil
+		}
+
+		return c.copyElements(node, src, dst)
+	case reflect.Map:
+		return c.copyMap(node, src, dst)
+	case reflect.Interface:
+		if src.IsNil() || (!c.opts.deepCopy && !c.restricted(src.Elem().Type())) {
+			dst.Set(src)
+
+			return nil
+		}
+
+		elem := reflect.New(src.Elem().Type()).Elem()
+		if err := c.copyValue(node, src.Elem(), elem); err != nil {
+			return err
+		}
+
+		dst.Set(elem)
+
+		return nil
+	default:
+		dst.Set(src)
+
+		return nil
+	}
+}
+
+func (c *copier) copyStruct(node Node, src, dst reflect.Value) error {
+	all := isAllIdentifiers(node)
+	if all {
+		// Copy the whole struct, unexported fields included, and duplicate the exported references or remove
+		// the restricted fields later if needed.
+		dst.Set(src)
+
+		if c.shares(node, src.Type()) {
+			return nil
+		}
+	}
+
+	t := src.Type()
+	tp := c.opts.planFor(t)
+
+	if all {
+		tp.detachEmbedded(dst)
+	}
+
+	for _, fp := range tp.fields {
+		child, ok := node, true
+		if fp.ignored {
+			// Ignored by JSON, so it can only be copied with the rest of the struct
+			ok = all && !fp.tag.restricted() && c.opts.allowed(t, fp)
+		} else {
+			child, ok = c.opts.selectedField(node, t, fp)
+		}
+
+		sf, exists := fieldByIndex(src, fp.index)
+		if !exists {
+			// promoted from a nil embedded pointer
+			continue
+		}
+
+		if !ok {
+			if all {
+				// copied with the rest of the struct, but it can't be selected
+				settableFieldByIndex(dst, fp.index).SetZero()
+			}
+
+			continue
+		}
+
+		if err := c.copyValue(child, sf, settableFieldByIndex(dst, fp.index)); err != nil {
+			return wrapFieldError(fp.name, err)
+		}
+	}
+
+	return nil
+}
+
+// copyLeaf copies a value as a whole, failing if a child selection is requested for it.
+func (c *copier) copyLeaf(node Node, src, dst reflect.Value) error {
+	if !isAllIdentifiers(node) {
+		return ErrChildSelectionOnLeaf
+	}
+
+	if c.opts.deepCopy && src.Kind() == reflect.Slice && !src.IsNil() {
+		s := reflect.MakeSlice(src.Type(), src.Len(), src.Len())
+		reflect.Copy(s, src)
+		dst.Set(s)
+
+		return nil
+	}
+
+	dst.Set(src)
+
+	return nil
+}
+
+func (c *copier) copyPtr(node Node, src, dst reflect.Value) error {
+	if src.IsNil() {
+		// source is nil; leave destination as zero (nil)
+		dst.SetZero()
+
+		return nil
+	}
+
+	elemType := src.Type().Elem()
+	if !c.opts.deepCopy && elemType.Kind() != reflect.Struct && !c.restricted(elemType) {
+		// Non-struct pointer: copy as is
+		dst.Set(src)
+
+		return nil
+	}
+
+	if c.reuse(node, src, dst) {
+		return nil
+	}
+
+	ptr := reflect.New(elemType)
+	dst.Set(ptr)
+	c.visit(node, src, ptr)
+
+	return c.copyValue(node, src.Elem(), ptr.Elem())
+}
+
+func (c *copier) copyElements(node Node, src, dst reflect.Value) error {
+	for i := range src.Len() {
+		if err := c.copyValue(node, src.Index(i), dst.Index(i)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// copyMap copies a map, when its keys are strings they are treated as JSON object keys and filtered by the selection.
+func (c *copier) copyMap(node Node, src, dst reflect.Value) error {
+	if src.IsNil() || c.shares(node, src.Type()) {
+		dst.Set(src)
+
+		return nil
+	}
+
+	if c.reuse(node, src, dst) {
+		return nil
+	}
+
+	stringKeys := src.Type().Key().Kind() == reflect.String
+	m := reflect.MakeMapWithSize(src.Type(), src.Len())
+	dst.Set(m)
+	c.visit(node, src, m)
+
+	iter := src.MapRange()
+	for iter.Next() {
+		child := node
+		if stringKeys {
+			ident, ok := c.opts.selectField(node, iter.Key().String())
+			if !ok {
+				continue
+			}
+
+			child = childNode(ident)
+		}
+
+		v := reflect.New(src.Type().Elem()).Elem()
+		if err := c.copyValue(child, iter.Value(), v); err != nil {
+			if stringKeys {
+				return wrapFieldError(iter.Key().String(), err)
+			}
+
+			return err
+		}
+
+		m.SetMapIndex(iter.Key(), v)
+	}
+
+	return nil
+}
+
+// reuse sets in dst the copy of the pointer, map or slice src if it was already reached with a wildcard
+// selection, so self-referencing values keep their shape instead of overflowing the stack.
+func (c *copier) reuse(node Node, src, dst reflect.Value) bool {
+	if !isAllIdentifiers(node) {
+		// a named selection is finite, so it can't go through a cycle forever
+		return false
+	}
+
+	v, ok := c.visited[newVisitKey(src)]
+	if ok {
+		dst.Set(v)
+	}
+
+	return ok
+}
+
+// visit records dup as the copy of the pointer, map or slice src when it's reached with a wildcard selection.
+func (c *copier) visit(node Node, src, dup reflect.Value) {
+	if !isAllIdentifiers(node) {
+		return
+	}
+
+	if c.visited == nil {
+		c.visited = make(map[visitKey]reflect.Value)
+	}
+
+	c.visited[newVisitKey(src)] = dup
+}
+
+// newVisitKey returns the key of the pointer, map or slice v.
+func newVisitKey(v reflect.Value) visitKey {
+	key := visitKey{ptr: v.Pointer(), typ: v.Type()}
+	if v.Kind() == reflect.Slice {
+		key.len = v.Len()
+	}
+
+	return key
+}
+
+// shares reports whether a value of type t can be shared with the source as it is, without going through it.
+func (c *copier) shares(node Node, t reflect.Type) bool {
+	return !c.opts.deepCopy && isAllIdentifiers(node) && !c.restricted(t)
+}
+
+func (c *copier) restricted(t reflect.Type) bool {
+	return c.opts.restricted(t)
+}
+
+// childNode returns the selection of the identifier children, a missing one means every child.
+func childNode(ident Identifier) Node {
+	if ident.Child == nil {
+		return AllIdentifiers{}
+	}
+
+	return ident.Child
+}
+
+func isAllIdentifiers(n Node) bool {
+	_, ok := n.(AllIdentifiers)
+
+	return ok
+}
+
+func isStructOrPtrToStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct || (t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct)
+}
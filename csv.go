@@ -0,0 +1,328 @@
+package gofieldselect
+
+import (
+	"database/sql/driver"
+	"encoding"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+type (
+	// csvColumn is a selected leaf path of the rows, e.g. `address.street`.
+	csvColumn struct {
+		header string
+		steps  []csvStep
+	}
+
+	// csvStep goes from a struct to one of its fields, or from a map to one of its keys.
+	csvStep struct {
+		index []int
+		key   string
+	}
+
+	// csvColumns builds the columns of a row type from a selection.
+	csvColumns struct {
+		opts    options
+		columns []csvColumn
+		// expanding holds the struct types being expanded with a wildcard selection, to skip recursive types.
+		expanding map[reflect.Type]struct{}
+	}
+)
+
+// WriteCSV writes [rows], a slice or array of structs or pointers to structs, as CSV to [w] with a column for each
+// selected leaf path, e.g. `address.street`, preceded by a header row.
+// The columns follow the order of the selection, and the declaration order for fields selected through a wildcard.
+// Nested slices return an error unless [WithSliceSeparator] is used, and recursive fields selected through a
+// wildcard are skipped. Selecting a field that doesn't exist returns a [FieldError] with [ErrUnknownField].
+func WriteCSV(w io.Writer, n Node, rows any, opts ...Option) error {
+	rv := reflect.ValueOf(rows)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return NewTypeNotValidError(rv.Kind())
+	}
+
+	rowType := rv.Type().Elem()
+	for rowType.Kind() == reflect.Ptr {
+		rowType = rowType.Elem()
+	}
+
+	if rowType.Kind() != reflect.Struct {
+		return NewTypeNotValidError(rowType.Kind())
+	}
+
+	cc := csvColumns{opts: newOptions(opts)}
+	if err := cc.add(n, rowType, "", nil); err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+
+	headers := make([]string, len(cc.columns))
+	for i, c := range cc.columns {
+		headers[i] = c.header
+	}
+
+	if err := cw.Write(headers); err != nil {
+		return err
+	}
+
+	record := make([]string, len(cc.columns))
+
+	for i := range rv.Len() {
+		for j, c := range cc.columns {
+			values, err := c.values(rv.Index(i), 0)
+			if err != nil {
+				return wrapFieldError(c.header, err)
+			}
+
+			record[j] = strings.Join(values, cc.opts.sliceSeparator)
+		}
+
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
+
+// add adds the columns of the type t, located in header, with the selection node.
+//
+//nolint:exhaustive // the rest of kinds are single columns
+func (cc *csvColumns) add(node Node, t reflect.Type, header string, steps []csvStep) error {
+	t = cc.derefType(t)
+
+	if isSliceOfValues(t) {
+		if cc.opts.sliceSeparator == "" {
+			return NewFieldError(header, ErrNestedSlice)
+		}
+
+		t = cc.derefType(t.Elem())
+	}
+
+	if isLeafType(t) || isBytes(t) {
+		if !isAllIdentifiers(node) {
+			return NewFieldError(header, ErrChildSelectionOnLeaf)
+		}
+
+		cc.columns = append(cc.columns, csvColumn{header: header, steps: steps})
+
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return cc.addStruct(node, t, header, steps)
+	case reflect.Map:
+		identifiers, ok := node.(Identifiers)
+		if !ok || t.Key().Kind() != reflect.String {
+			cc.columns = append(cc.columns, csvColumn{header: header, steps: steps})
+
+			return nil
+		}
+
+		for _, ident := range identifiers {
+			err := cc.add(childNode(ident), t.Elem(), joinPath(header, ident.Value),
+				appendStep(steps, csvStep{key: ident.Value}))
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	default:
+		cc.columns = append(cc.columns, csvColumn{header: header, steps: steps})
+
+		return nil
+	}
+}
+
+// addStruct adds the columns of the selected fields of a struct, in selection order.
+func (cc *csvColumns) addStruct(node Node, t reflect.Type, header string, steps []csvStep) error {
+	tp := cc.opts.planFor(t)
+
+	identifiers, ok := node.(Identifiers)
+	if !ok {
+		if _, expanding := cc.expanding[t]; expanding {
+			// a recursive type can't be flattened into columns
+			return nil
+		}
+
+		if cc.expanding == nil {
+			cc.expanding = make(map[reflect.Type]struct{})
+		}
+
+		cc.expanding[t] = struct{}{}
+		defer delete(cc.expanding, t)
+
+		for _, fp := range tp.fields {
+			if err := cc.addField(node, t, fp, header, steps); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	named := make(map[string]struct{}, len(identifiers))
+
+	for _, ident := range identifiers {
+		fp, found := cc.opts.field(tp, ident.Value)
+		if !found || fp.tag.mode == modeNever {
+			return NewFieldError(joinPath(header, ident.Value), ErrUnknownField)
+		}
+
+		named[fp.name] = struct{}{}
+
+		if err := cc.addField(node, t, fp, header, steps); err != nil {
+			return err
+		}
+	}
+
+	// the `always` fields not named go after the selected ones
+	for _, fp := range tp.fields {
+		if _, ok := named[fp.name]; !ok && fp.tag.mode == modeAlways {
+			if err := cc.addField(node, t, fp, header, steps); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// addField adds the columns of a field of the struct type t if it's selected in node.
+func (cc *csvColumns) addField(node Node, t reflect.Type, fp fieldPlan, header string, steps []csvStep) error {
+	if fp.ignored {
+		return nil
+	}
+
+	child, ok := cc.opts.selectedField(node, t, fp)
+	if !ok {
+		return nil
+	}
+
+	return cc.add(child, fp.typ, joinPath(header, fp.name), appendStep(steps, csvStep{index: fp.index}))
+}
+
+func (cc *csvColumns) derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t
+}
+
+// values returns the formatted values of the column in v, more than one when going through slices.
+//
+//nolint:exhaustive // the rest of kinds are followed by the steps
+func (c csvColumn) values(v reflect.Value, step int) ([]string, error) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, nil
+		}
+
+		v = v.Elem()
+	}
+
+	if isSliceOfValues(v.Type()) {
+		var values []string
+
+		for i := range v.Len() {
+			elemValues, err := c.values(v.Index(i), step)
+			if err != nil {
+				return nil, err
+			}
+
+			values = append(values, elemValues...)
+		}
+
+		return values, nil
+	}
+
+	if step == len(c.steps) {
+		s, err := formatCSVValue(v)
+		if err != nil {
+			return nil, err
+		}
+
+		return []string{s}, nil
+	}
+
+	s := c.steps[step]
+
+	switch v.Kind() {
+	case reflect.Struct:
+		fv, ok := fieldByIndex(v, s.index)
+		if !ok {
+			return nil, nil
+		}
+
+		return c.values(fv, step+1)
+	case reflect.Map:
+		mv := v.MapIndex(reflect.ValueOf(s.key).Convert(v.Type().Key()))
+		if !mv.IsValid() {
+			return nil, nil
+		}
+
+		return c.values(mv, step+1)
+	default:
+		return nil, nil
+	}
+}
+
+// formatCSVValue formats a single value, using its text, database or JSON representation if it has one.
+func formatCSVValue(v reflect.Value) (string, error) {
+	value := v.Interface()
+	if v.CanAddr() {
+		value = v.Addr().Interface()
+	}
+
+	switch tv := value.(type) {
+	case encoding.TextMarshaler:
+		b, err := tv.MarshalText()
+
+		return string(b), err
+	case driver.Valuer:
+		dv, err := tv.Value()
+		if err != nil || dv == nil {
+			return "", err
+		}
+
+		return fmt.Sprint(dv), nil
+	case json.Marshaler:
+		b, err := tv.MarshalJSON()
+
+		return string(b), err
+	}
+
+	if isBytes(v.Type()) {
+		return string(v.Bytes()), nil
+	}
+
+	if v.Kind() == reflect.Map || v.Kind() == reflect.Struct {
+		b, err := json.Marshal(value)
+
+		return string(b), err
+	}
+
+	return fmt.Sprint(v.Interface()), nil
+}
+
+// isSliceOfValues reports whether t is a slice or array whose elements are separate values, not bytes.
+func isSliceOfValues(t reflect.Type) bool {
+	return (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && !isLeafType(t) && !isBytes(t)
+}
+
+func isBytes(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8
+}
+
+func appendStep(steps []csvStep, step csvStep) []csvStep {
+	return append(steps[:len(steps):len(steps)], step)
+}
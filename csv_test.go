@@ -0,0 +1,133 @@
+package gofieldselect
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+type (
+	orderLine struct {
+		Product string `json:"product"`
+		Amount  int    `json:"amount"`
+	}
+
+	order struct {
+		ID        int               `json:"id"`
+		Customer  *UserPtr          `json:"customer"`
+		CreatedAt time.Time         `json:"createdAt"`
+		Note      sql.NullString    `json:"note"`
+		Tags      []string          `json:"tags"`
+		Lines     []orderLine       `json:"lines"`
+		Labels    map[string]string `json:"labels"`
+		Parent    *order            `json:"parent"`
+	}
+)
+
+func newOrders() []*order {
+	return []*order{
+		{
+			ID:        1,
+			Customer:  &UserPtr{Name: "John", Address: &Address{Street: "Main, 1", Number: 1}},
+			CreatedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			Note:      sql.NullString{String: "fragile", Valid: true},
+			Tags:      []string{"a", "b"},
+			Lines:     []orderLine{{Product: "pen", Amount: 2}, {Product: "ink", Amount: 1}},
+			Labels:    map[string]string{"env": "prod"},
+		},
+		{ID: 2},
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		selection string
+		opts      []Option
+		expected  string
+	}{
+		"selection order and nested paths": {
+			selection: "customer(address(street),name),id,createdAt,note",
+			expected: "customer.address.street,customer.name,id,createdAt,note\n" +
+				"\"Main, 1\",John,1,2025-01-01T00:00:00Z,fragile\n" +
+				",,2,0001-01-01T00:00:00Z,\n",
+		},
+		"wildcard struct": {
+			selection: "id,customer(address)",
+			expected: "id,customer.address.street,customer.address.number\n" +
+				"1,\"Main, 1\",1\n" +
+				"2,,\n",
+		},
+		"joined slices and map keys": {
+			selection: "tags,lines(product),labels(env)",
+			opts:      []Option{WithSliceSeparator("|")},
+			expected: "tags,lines.product,labels.env\n" +
+				"a|b,pen|ink,prod\n" +
+				",,\n",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			if err := WriteCSV(&buf, parse(t, tc.selection), newOrders(), tc.opts...); err != nil {
+				t.Fatalf("error: %v", err)
+			}
+
+			if buf.String() != tc.expected {
+				t.Fatalf("expected %q; got %q", tc.expected, buf.String())
+			}
+		})
+	}
+}
+
+func TestWriteCSVErrors(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	err := WriteCSV(&buf, parse(t, "id,tags"), newOrders())
+	if !errors.Is(err, ErrNestedSlice) {
+		t.Fatalf("expected ErrNestedSlice; got %v", err)
+	}
+
+	err = WriteCSV(&buf, parse(t, "createdAt(foo)"), newOrders())
+	if !errors.Is(err, ErrChildSelectionOnLeaf) {
+		t.Fatalf("expected ErrChildSelectionOnLeaf; got %v", err)
+	}
+
+	err = WriteCSV(&buf, parse(t, "id,bogus"), newOrders())
+
+	var fe FieldError
+	if !errors.Is(err, ErrUnknownField) || !errors.As(err, &fe) || fe.Path() != "bogus" {
+		t.Fatalf("expected ErrUnknownField for bogus; got %v", err)
+	}
+
+	err = WriteCSV(&buf, parse(t, "id"), order{})
+	if !errors.As(err, &TypeNotValidError{}) {
+		t.Fatalf("expected TypeNotValidError; got %v", err)
+	}
+}
+
+func TestWriteCSVRecursiveWildcard(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, parse(t, "id,parent"), newOrders(), WithSliceSeparator("|")); err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	expected := "id,parent.id,parent.customer.name,parent.customer.surname,parent.customer.age," +
+		"parent.customer.address.street,parent.customer.address.number,parent.createdAt,parent.note,parent.tags," +
+		"parent.lines.product,parent.lines.amount,parent.labels\n" +
+		"1,,,,,,,,,,,,\n" +
+		"2,,,,,,,,,,,,\n"
+	if buf.String() != expected {
+		t.Fatalf("expected %q; got %q", expected, buf.String())
+	}
+}
@@ -0,0 +1,79 @@
+package gofieldselect
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// ExpandDependencies returns the selection [n] with the fields its selected fields of [T] depend on, declared with
+// the `fieldselect:"requires=..."` tag, e.g. `fieldselect:"requires=name,surname"` for a computed `fullName`.
+// The returned selection is the one to fetch from the data layer, while the response is still projected with [n].
+// The dependencies of the added fields are added too, and the field names follow the [WithTagKey], [WithNaming]
+// and [WithCaseInsensitive] options.
+// It panics if the `requires` tag of a selected field isn't a valid selection, which [Validate] reports as an error.
+func ExpandDependencies[T any](n Node, opts ...Option) Node {
+	return expandDependencies(newOptions(opts), n, reflect.TypeFor[T]())
+}
+
+//nolint:exhaustive // the rest of kinds don't have fields
+func expandDependencies(o options, n Node, t reflect.Type) Node {
+	identifiers, ok := n.(Identifiers)
+	if !ok {
+		return n
+	}
+
+	t = elemType(t)
+
+	switch t.Kind() {
+	case reflect.Struct:
+		tp := o.planFor(t)
+
+		// add the dependencies of the selected fields until there are no new ones
+		var expanded Node = identifiers
+		for {
+			next := expanded
+
+			//nolint:errcheck // merging identifiers always returns identifiers
+			for _, ident := range expanded.(Identifiers) {
+				fp, found := o.field(tp, ident.Value)
+				if !found {
+					continue
+				}
+
+				if fp.tag.requiresErr != nil {
+					panic(fmt.Sprintf("gofieldselect: field %s: %v", fp.name, fp.tag.requiresErr))
+				}
+
+				if fp.tag.requires != nil {
+					next = mergeNodes(next, fp.tag.requires)
+				}
+			}
+
+			if canonical(next) == canonical(expanded) {
+				break
+			}
+
+			expanded = next
+		}
+
+		//nolint:errcheck // merging identifiers always returns identifiers
+		result := slices.Clone(expanded.(Identifiers))
+		for i, ident := range result {
+			if fp, found := o.field(tp, ident.Value); found {
+				result[i].Child = expandDependencies(o, childNode(ident), fp.typ)
+			}
+		}
+
+		return result
+	case reflect.Map:
+		result := make(Identifiers, len(identifiers))
+		for i, ident := range identifiers {
+			result[i] = Identifier{Value: ident.Value, Child: expandDependencies(o, childNode(ident), t.Elem())}
+		}
+
+		return result
+	default:
+		return n
+	}
+}
@@ -0,0 +1,85 @@
+package gofieldselect
+
+import (
+	"errors"
+	"testing"
+)
+
+type (
+	person struct {
+		Name     string  `json:"name"`
+		Surname  string  `json:"surname"`
+		FullName string  `json:"fullName" fieldselect:"requires=name,surname"`
+		Greeting string  `json:"greeting" fieldselect:"requires=fullName,home(city)"`
+		Home     home    `json:"home"`
+		Friends  []*home `json:"friends"`
+	}
+
+	home struct {
+		City    string `json:"city"`
+		Country string `json:"country"`
+		Label   string `json:"label"   fieldselect:"requires=city,country"`
+	}
+)
+
+func TestExpandDependencies(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"fullName":             "(fullName,name,surname)",
+		"greeting":             "(fullName,greeting,home(city),name,surname)",
+		"greeting,home(label)": "(fullName,greeting,home(city,country,label),name,surname)",
+		"friends(label)":       "(friends(city,country,label))",
+		"name":                 "(name)",
+		"":                     "*",
+	}
+
+	for sel, expected := range tests {
+		t.Run(sel, func(t *testing.T) {
+			t.Parallel()
+
+			n := parse(t, sel)
+			before := canonical(n)
+
+			if got := canonical(ExpandDependencies[person](n)); got != expected {
+				t.Fatalf("expected %s, got %s", expected, got)
+			}
+
+			if canonical(n) != before {
+				t.Fatalf("expected the selection unchanged, got %s", canonical(n))
+			}
+		})
+	}
+}
+
+func TestExpandDependenciesInvalidRequires(t *testing.T) {
+	t.Parallel()
+
+	type invalid struct {
+		Name     string `json:"name"`
+		FullName string `json:"fullName" fieldselect:"requires=name,(surname"`
+	}
+
+	// the other fields and APIs aren't affected by the invalid tag
+	if got := canonical(ExpandDependencies[invalid](parse(t, "name"))); got != "(name)" {
+		t.Fatalf("expected (name), got %s", got)
+	}
+
+	if _, err := Marshal(parse(t, "fullName"), invalid{Name: "n", FullName: "f"}); err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	var fe FieldError
+	if err := Validate[invalid](parse(t, "fullName")); !errors.As(err, &fe) || fe.Path() != "fullName" ||
+		!errors.Is(err, ErrInvalidRequires) {
+		t.Fatalf("expected an invalid requires error for fullName, got %v", err)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatalf("expected a panic for the invalid requires tag")
+		}
+	}()
+
+	ExpandDependencies[invalid](parse(t, "fullName"))
+}
@@ -0,0 +1,188 @@
+package gofieldselect
+
+import (
+	"strings"
+
+	"github.com/golaxo/gofieldselect/internal/lexer"
+)
+
+// Dialect is the syntax of a field selection.
+type Dialect int
+
+const (
+	// DefaultDialect is the syntax of [Parse], e.g. `name,address(street,number)`.
+	DefaultDialect Dialect = iota
+	// GoogleFields is the syntax of the Google APIs partial responses, where `/` separates the fields of a path
+	// and `*` selects every field, e.g. `items/id,items(name,kind),etag/*`.
+	GoogleFields
+	// GraphQL is the syntax of the GraphQL selection sets, with `#` comments and aliases,
+	// e.g. `{ name home: address { street } }`.
+	GraphQL
+)
+
+// ParseOptions configures how a field selection is parsed, see [ParseWithOptions].
+type ParseOptions struct {
+	Dialect Dialect
+}
+
+// ParseWithOptions parses the field selection with the syntax of the dialect in [opts], returning the same
+// [Node] tree as [Parse].
+func ParseWithOptions(fieldSelection string, opts ParseOptions) (Node, error) {
+	var p *parser
+
+	switch opts.Dialect {
+	case GoogleFields:
+		p = newParser(lexer.NewWithPaths(fieldSelection))
+		p.paths = true
+	case GraphQL:
+		p = newParser(lexer.NewGraphQL(fieldSelection))
+	default:
+		return Parse(fieldSelection)
+	}
+
+	var n Node
+	if opts.Dialect == GraphQL {
+		n = p.parseGraphQL()
+	} else {
+		n = p.parse()
+	}
+
+	if len(p.Errors()) > 0 {
+		return nil, NewParsingError(p.Errors())
+	}
+
+	return n, nil
+}
+
+// Format returns the field selection of [n] with the syntax of [dialect], so parsing it returns the same selection.
+// Every field is selected with an empty string in the [DefaultDialect] and [GraphQL], and with `*` in [GoogleFields].
+// The selections that can't be written so, a [FieldError] with the path of the field, are:
+//   - A selection without fields, [ErrEmptySelection], since it would be read as every field. In [GraphQL] the
+//     fields with an empty child selection, e.g. `address()`, can't be written either.
+//   - A field name with a delimiter of the dialect, like `,` or `(`, [ErrInvalidFieldName]. In [GraphQL] the names
+//     can't start with `...` or `@` either, since they would be read as fragments or directives.
+func Format(n Node, dialect Dialect) (string, error) {
+	if err := checkFormat(n, dialect); err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+
+	switch dialect {
+	case GoogleFields:
+		if isAllIdentifiers(n) {
+			return "*", nil
+		}
+
+		formatGoogleFields(&sb, n)
+	case GraphQL:
+		formatGraphQL(&sb, n)
+	default:
+		formatDefault(&sb, n)
+	}
+
+	return sb.String(), nil
+}
+
+// checkFormat returns the error of the first part of n that parsing its format in the dialect wouldn't return.
+func checkFormat(n Node, dialect Dialect) error {
+	identifiers, ok := n.(Identifiers)
+	if !ok {
+		return nil
+	}
+
+	if len(identifiers) == 0 {
+		return ErrEmptySelection
+	}
+
+	for _, ident := range identifiers {
+		if !isFormattableName(ident.Value, dialect) {
+			return NewFieldError(ident.Value, ErrInvalidFieldName)
+		}
+
+		child := childNode(ident)
+		if children, ok := child.(Identifiers); ok && len(children) == 0 && dialect != GraphQL {
+			// written as `address()`
+			continue
+		}
+
+		if err := checkFormat(child, dialect); err != nil {
+			return wrapFieldError(ident.Value, err)
+		}
+	}
+
+	return nil
+}
+
+// isFormattableName reports whether the name is read back as a single identifier in the dialect.
+func isFormattableName(name string, dialect Dialect) bool {
+	delimiters := " \t\r\n,()"
+
+	switch dialect {
+	case GoogleFields:
+		delimiters += "/*"
+	case GraphQL:
+		if strings.HasPrefix(name, graphQLFragment) || strings.HasPrefix(name, graphQLDirective) {
+			return false
+		}
+
+		delimiters += "{}:#"
+	default:
+	}
+
+	return name != "" && !strings.ContainsAny(name, delimiters)
+}
+
+func formatDefault(sb *strings.Builder, n Node) {
+	identifiers, ok := n.(Identifiers)
+	if !ok {
+		return
+	}
+
+	for i, ident := range identifiers {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+
+		sb.WriteString(ident.Value)
+
+		if child := childNode(ident); !isAllIdentifiers(child) {
+			sb.WriteByte('(')
+			formatDefault(sb, child)
+			sb.WriteByte(')')
+		}
+	}
+}
+
+// formatGoogleFields writes the identifiers of n, the fields with a single child as paths, e.g. `items/id`.
+func formatGoogleFields(sb *strings.Builder, n Node) {
+	identifiers, ok := n.(Identifiers)
+	if !ok {
+		return
+	}
+
+	for i, ident := range identifiers {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+
+		sb.WriteString(ident.Value)
+
+		child := childNode(ident)
+		if isAllIdentifiers(child) {
+			continue
+		}
+
+		//nolint:errcheck // it isn't every field, so it's identifiers
+		if children := child.(Identifiers); len(children) == 1 {
+			sb.WriteByte('/')
+			formatGoogleFields(sb, children)
+
+			continue
+		}
+
+		sb.WriteByte('(')
+		formatGoogleFields(sb, child)
+		sb.WriteByte(')')
+	}
+}
@@ -0,0 +1,218 @@
+package gofieldselect
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseGoogleFields(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"items/id,items(name,kind)":       "(items(id,kind,name))",
+		"etag,items/author/displayName":   "(etag,items(author(displayName)))",
+		"items/pagemap/*":                 "(items(pagemap))",
+		"*":                               "*",
+		"items(*),kind":                   "(items,kind)",
+		"kind,*":                          "*",
+		"items(id,author/email),items/id": "(items(author(email),id))",
+	}
+
+	for sel, expected := range tests {
+		n, err := ParseWithOptions(sel, ParseOptions{Dialect: GoogleFields})
+		if err != nil {
+			t.Fatalf("%q: error: %v", sel, err)
+		}
+
+		if got := canonical(n); got != expected {
+			t.Fatalf("%q: expected %s, got %s", sel, expected, got)
+		}
+	}
+}
+
+func TestParseGoogleFieldsErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, sel := range []string{"items/*/id", "items/", "items//id", "items(id"} {
+		if _, err := ParseWithOptions(sel, ParseOptions{Dialect: GoogleFields}); err == nil {
+			t.Fatalf("%q: expected an error", sel)
+		}
+	}
+
+	_, err := ParseWithOptions("items/*/id", ParseOptions{Dialect: GoogleFields})
+	if !errors.Is(err, ErrUnexpectedWildcard) {
+		t.Fatalf("expected ErrUnexpectedWildcard, got %v", err)
+	}
+}
+
+func TestParseGraphQL(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"{ name address { street } }":                     "(address(street),name)",
+		"name, address { street number }":                 "(address(number,street),name)",
+		"{\n  name # the full name\n  age\n}":             "(age,name)",
+		"{ home: address { street } address { number } }": "(address(number,street))",
+		"{ fullName : name }":                             "(name)",
+		"# nothing selected\n":                            "*",
+	}
+
+	for sel, expected := range tests {
+		n, err := ParseWithOptions(sel, ParseOptions{Dialect: GraphQL})
+		if err != nil {
+			t.Fatalf("%q: error: %v", sel, err)
+		}
+
+		if got := canonical(n); got != expected {
+			t.Fatalf("%q: expected %s, got %s", sel, expected, got)
+		}
+	}
+}
+
+func TestParseGraphQLErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, sel := range []string{"{ }", "{ name", "{ name } }", "{ alias: }", "{ address {} }", "{ name(id) }"} {
+		if _, err := ParseWithOptions(sel, ParseOptions{Dialect: GraphQL}); err == nil {
+			t.Fatalf("%q: expected an error", sel)
+		}
+	}
+
+	tests := map[string]error{
+		"{ address { street }":                ErrExpectedClosingBrace,
+		"{ name } }":                          ErrUnexpectedToken,
+		"{ ...UserFields name }":              ErrUnsupportedFragment,
+		"{ ... on User { name } }":            ErrUnsupportedFragment,
+		"{ alias: ...UserFields }":            ErrUnsupportedFragment,
+		"{ name @include }":                   ErrUnsupportedDirective,
+		"{ name @include(if: $all) address }": ErrUnsupportedDirective,
+	}
+
+	for sel, expected := range tests {
+		if _, err := ParseWithOptions(sel, ParseOptions{Dialect: GraphQL}); !errors.Is(err, expected) {
+			t.Fatalf("%q: expected %v, got %v", sel, expected, err)
+		}
+	}
+}
+
+func TestParseWithOptionsDefaultDialect(t *testing.T) {
+	t.Parallel()
+
+	n, err := ParseWithOptions("a/b,c*", ParseOptions{})
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	if got := canonical(n); got != "(a/b,c*)" {
+		t.Fatalf("expected `/` and `*` to be part of the names, got %s", got)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	t.Parallel()
+
+	n := parse(t, "etag,items(id,author(name)),kind(x,y)")
+
+	if got := format(t, n, DefaultDialect); got != "etag,items(id,author(name)),kind(x,y)" {
+		t.Fatalf("unexpected default format %q", got)
+	}
+
+	got := format(t, n, GoogleFields)
+	if got != "etag,items(id,author/name),kind(x,y)" {
+		t.Fatalf("unexpected Google fields format %q", got)
+	}
+
+	parsed, err := ParseWithOptions(got, ParseOptions{Dialect: GoogleFields})
+	if err != nil || canonical(parsed) != canonical(n) {
+		t.Fatalf("expected the same selection, got %v, %v", parsed, err)
+	}
+
+	got = format(t, n, GraphQL)
+	if got != "{ etag items { id author { name } } kind { x y } }" {
+		t.Fatalf("unexpected GraphQL format %q", got)
+	}
+
+	parsed, err = ParseWithOptions(got, ParseOptions{Dialect: GraphQL})
+	if err != nil || canonical(parsed) != canonical(n) {
+		t.Fatalf("expected the same selection, got %v, %v", parsed, err)
+	}
+
+	if got = format(t, AllIdentifiers{}, GoogleFields); got != "*" {
+		t.Fatalf("expected a wildcard, got %q", got)
+	}
+}
+
+func TestFormatRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	for _, dialect := range []Dialect{DefaultDialect, GoogleFields, GraphQL} {
+		for _, sel := range []string{"", "name", "a(b(c)),d", "items(id,author(name(first,last)))"} {
+			n := parse(t, sel)
+
+			parsed, err := ParseWithOptions(format(t, n, dialect), ParseOptions{Dialect: dialect})
+			if err != nil || canonical(parsed) != canonical(n) {
+				t.Fatalf("%d %q: expected the same selection, got %v, %v", dialect, sel, parsed, err)
+			}
+		}
+	}
+
+	for _, dialect := range []Dialect{DefaultDialect, GoogleFields} {
+		n := parse(t, "name,address()")
+
+		parsed, err := ParseWithOptions(format(t, n, dialect), ParseOptions{Dialect: dialect})
+		if err != nil || canonical(parsed) != canonical(n) {
+			t.Fatalf("%d: expected the empty child kept, got %v, %v", dialect, parsed, err)
+		}
+	}
+}
+
+func TestFormatErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		n        Node
+		dialect  Dialect
+		path     string
+		expected error
+	}{
+		{Identifiers{}, DefaultDialect, "", ErrEmptySelection},
+		{Identifiers{}, GoogleFields, "", ErrEmptySelection},
+		{Identifiers{}, GraphQL, "", ErrEmptySelection},
+		{Identifiers{{Value: "name"}, {Value: "address", Child: Identifiers{}}}, GraphQL, "address", ErrEmptySelection},
+		{Identifiers{{Value: "a,b"}}, DefaultDialect, "a,b", ErrInvalidFieldName},
+		{Identifiers{{Value: "a/b"}}, GoogleFields, "a/b", ErrInvalidFieldName},
+		{Identifiers{{Value: ""}}, DefaultDialect, "", ErrInvalidFieldName},
+		{Identifiers{{Value: "a", Child: Identifiers{{Value: "b{"}}}}, GraphQL, "a.b{", ErrInvalidFieldName},
+		{Identifiers{{Value: "a:b"}}, GraphQL, "a:b", ErrInvalidFieldName},
+		{Identifiers{{Value: "a#b"}}, GraphQL, "a#b", ErrInvalidFieldName},
+		{Identifiers{{Value: "...f"}}, GraphQL, "...f", ErrInvalidFieldName},
+		{Identifiers{{Value: "@f"}}, GraphQL, "@f", ErrInvalidFieldName},
+	}
+
+	for _, tt := range tests {
+		_, err := Format(tt.n, tt.dialect)
+		if !errors.Is(err, tt.expected) {
+			t.Fatalf("%s %d: expected %v, got %v", canonical(tt.n), tt.dialect, tt.expected, err)
+		}
+
+		var fe FieldError
+		if tt.path != "" && (!errors.As(err, &fe) || fe.Path() != tt.path) {
+			t.Fatalf("%s %d: expected the path %q, got %v", canonical(tt.n), tt.dialect, tt.path, err)
+		}
+	}
+
+	if got, err := Format(parse(t, "a:b"), DefaultDialect); err != nil || got != "a:b" {
+		t.Fatalf("expected a colon allowed in the default dialect, got %q, %v", got, err)
+	}
+}
+
+func format(t *testing.T, n Node, dialect Dialect) string {
+	t.Helper()
+
+	s, err := Format(n, dialect)
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	return s
+}
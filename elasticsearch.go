@@ -0,0 +1,100 @@
+package gofieldselect
+
+import (
+	"slices"
+	"strings"
+)
+
+const (
+	// sourceIncludes and sourceExcludes are the keys of the Elasticsearch `_source` filter.
+	sourceIncludes = "includes"
+	sourceExcludes = "excludes"
+	// sourceWildcard is the suffix of an Elasticsearch path that selects every child, e.g. `address.*`.
+	sourceWildcard = pathSeparator + "*"
+)
+
+// ToSourceFilter returns the Elasticsearch `_source` filter of the selection [n] with the [excludes] paths,
+// e.g. `{"includes": ["address.street"], "excludes": ["password"]}`.
+// The includes are sorted and omitted when every field is selected, like the excludes when there are none.
+// A selection without fields, e.g. `address()`, returns [ErrEmptySelection], since empty includes select every
+// field.
+func ToSourceFilter(n Node, excludes ...string) (map[string]any, error) {
+	filter := make(map[string]any, 2)
+
+	if includes := nodePaths(n); includes != nil {
+		if len(includes) == 0 {
+			return nil, ErrEmptySelection
+		}
+
+		slices.Sort(includes)
+		filter[sourceIncludes] = includes
+	}
+
+	if len(excludes) > 0 {
+		filter[sourceExcludes] = slices.Sorted(slices.Values(excludes))
+	}
+
+	return filter, nil
+}
+
+// FromSourceFilter returns the selection and the excluded paths of an Elasticsearch `_source` filter, the inverse of
+// [ToSourceFilter].
+// The includes and excludes can be string slices or, as decoded from JSON, slices of strings as any, and a path
+// can end with `.*` to select every child. Other wildcards return [ErrInvalidProjection].
+func FromSourceFilter(filter map[string]any) (Node, []string, error) {
+	includes, err := sourcePaths(filter, sourceIncludes)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	excludes, err := sourcePaths(filter, sourceExcludes)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	n, err := nodeFromPaths(includes)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return n, excludes, nil
+}
+
+// sourcePaths returns the paths of the key of the filter, without the `.*` suffixes.
+func sourcePaths(filter map[string]any, key string) ([]string, error) {
+	var paths []string
+
+	switch v := filter[key].(type) {
+	case nil:
+		return nil, nil
+	case []string:
+		paths = slices.Clone(v)
+	case []any:
+		for _, p := range v {
+			s, ok := p.(string)
+			if !ok {
+				return nil, NewFieldError(key, ErrInvalidProjection)
+			}
+
+			paths = append(paths, s)
+		}
+	default:
+		return nil, NewFieldError(key, ErrInvalidProjection)
+	}
+
+	for i, p := range paths {
+		if p == "*" && key == sourceIncludes {
+			// every field
+			return nil, nil
+		}
+
+		p = strings.TrimSuffix(p, sourceWildcard)
+		if strings.Contains(p, "*") {
+			return nil, NewFieldError(p, ErrInvalidProjection)
+		}
+
+		paths[i] = p
+	}
+
+	return paths, nil
+}
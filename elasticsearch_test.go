@@ -0,0 +1,71 @@
+package gofieldselect
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestToSourceFilter(t *testing.T) {
+	t.Parallel()
+
+	got, err := json.Marshal(sourceFilter(t, parse(t, "name,address(street),tags()"), "password"))
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	expected := `{"excludes":["password"],"includes":["address.street","name"]}`
+	if string(got) != expected {
+		t.Fatalf("expected %s, got %s", expected, got)
+	}
+
+	if filter := sourceFilter(t, parse(t, "")); len(filter) != 0 {
+		t.Fatalf("expected an empty filter, got %v", filter)
+	}
+
+	if _, err = ToSourceFilter(parse(t, "address()"), "password"); !errors.Is(err, ErrEmptySelection) {
+		t.Fatalf("expected ErrEmptySelection, got %v", err)
+	}
+}
+
+func sourceFilter(t *testing.T, n Node, excludes ...string) map[string]any {
+	t.Helper()
+
+	filter, err := ToSourceFilter(n, excludes...)
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	return filter
+}
+
+func TestFromSourceFilter(t *testing.T) {
+	t.Parallel()
+
+	var filter map[string]any
+	if err := json.Unmarshal([]byte(`{"includes":["name","address.*","tags"],"excludes":["password"]}`),
+		&filter); err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	n, excludes, err := FromSourceFilter(filter)
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	if canonical(n) != "(address,name,tags)" || !slices.Equal(excludes, []string{"password"}) {
+		t.Fatalf("expected the selection and exclusions, got %s and %v", canonical(n), excludes)
+	}
+
+	sel := "name,address(street,number)"
+
+	n, _, err = FromSourceFilter(sourceFilter(t, parse(t, sel)))
+	if err != nil || canonical(n) != canonical(parse(t, sel)) {
+		t.Fatalf("expected the same selection, got %v, %v", n, err)
+	}
+
+	if _, _, err = FromSourceFilter(map[string]any{"includes": []string{"addr*"}}); !errors.Is(err, ErrInvalidProjection) {
+		t.Fatalf("expected ErrInvalidProjection, got %v", err)
+	}
+}
@@ -9,10 +9,33 @@ import (
 
 var (
 	_ error = new(ParsingError)
+	_ error = new(ValidationError)
+	_ error = new(FieldError)
+	_ error = new(ResolveError)
 
 	ErrExpectedIdentifier                 = errors.New("expected identifier")
 	ErrMissingSeparatorBetweenIdentifiers = errors.New("missing separator between identifiers")
 	ErrExpectedClosingParenthesis         = errors.New("expected closing parenthesis")
+	ErrExpectedClosingBrace               = errors.New("expected closing brace")
+	ErrUnknownField                       = errors.New("unknown field")
+	ErrChildSelectionOnLeaf               = errors.New("child selection on a field without children")
+	ErrIncompatibleTypes                  = errors.New("incompatible types")
+	ErrCyclicValue                        = errors.New("cyclic value")
+	ErrNestedSlice                        = errors.New("nested slice")
+	ErrUnmappedField                      = errors.New("field not mapped to a column")
+	ErrInvalidProjection                  = errors.New("invalid projection")
+	ErrUnexpectedWildcard                 = errors.New("wildcard with children")
+	ErrUnsupportedOption                  = errors.New("unsupported option")
+	ErrMultipleJSONValues                 = errors.New("more than one top-level JSON value")
+	ErrMissingResourceType                = errors.New("resource without type")
+	ErrEmptySelection                     = errors.New("selection without fields")
+	ErrNestedSelection                    = errors.New("nested selection")
+	ErrUnexpectedToken                    = errors.New("unexpected token")
+	ErrUnsupportedFragment                = errors.New("fragments are not supported")
+	ErrUnsupportedDirective               = errors.New("directives are not supported")
+	ErrInvalidFieldName                   = errors.New("invalid field name")
+	ErrInvalidRequires                    = errors.New("invalid requires tag")
+	ErrRecursiveType                      = errors.New("recursive type with restricted fields")
 )
 
 type (
@@ -23,6 +46,23 @@ type (
 	TypeNotValidError struct {
 		kind reflect.Kind
 	}
+
+	// ValidationError holds all the errors found validating a selection against a type.
+	ValidationError struct {
+		errSlice []error
+	}
+
+	// ResolveError holds the errors of the resolvers or loaders that failed, each one a [FieldError] with the path
+	// of its field.
+	ResolveError struct {
+		errSlice []error
+	}
+
+	// FieldError is an error related to the field in the given path, e.g. `address.street`.
+	FieldError struct {
+		path string
+		err  error
+	}
 )
 
 func NewParsingError(errSlice []error) ParsingError {
@@ -38,6 +78,10 @@ func (pe ParsingError) Error() string {
 	return strings.Join(ss, ",")
 }
 
+func (pe ParsingError) Unwrap() []error {
+	return pe.errSlice
+}
+
 func NewTypeNotValidError(kind reflect.Kind) TypeNotValidError {
 	return TypeNotValidError{kind: kind}
 }
@@ -45,3 +89,72 @@ func NewTypeNotValidError(kind reflect.Kind) TypeNotValidError {
 func (e TypeNotValidError) Error() string {
 	return fmt.Sprintf("Kind must be a struct or pointer to struct, got %q", e.kind)
 }
+
+func NewValidationError(errSlice []error) ValidationError {
+	return ValidationError{errSlice: errSlice}
+}
+
+func (ve ValidationError) Error() string {
+	ss := make([]string, len(ve.errSlice))
+	for i, e := range ve.errSlice {
+		ss[i] = e.Error()
+	}
+
+	return strings.Join(ss, ",")
+}
+
+func (ve ValidationError) Unwrap() []error {
+	return ve.errSlice
+}
+
+func NewResolveError(errSlice []error) ResolveError {
+	return ResolveError{errSlice: errSlice}
+}
+
+func (re ResolveError) Error() string {
+	ss := make([]string, len(re.errSlice))
+	for i, e := range re.errSlice {
+		ss[i] = e.Error()
+	}
+
+	return strings.Join(ss, ",")
+}
+
+func (re ResolveError) Unwrap() []error {
+	return re.errSlice
+}
+
+func NewFieldError(path string, err error) FieldError {
+	return FieldError{path: path, err: err}
+}
+
+// Path returns the dot separated path of the field, e.g. `address.street`.
+func (e FieldError) Path() string {
+	return e.path
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.path, e.err)
+}
+
+func (e FieldError) Unwrap() error {
+	return e.err
+}
+
+// wrapFieldError prefixes the path of err with the field name, or creates a [FieldError] for it.
+func wrapFieldError(name string, err error) error {
+	var fe FieldError
+	if errors.As(err, &fe) {
+		return NewFieldError(joinPath(name, fe.path), fe.err)
+	}
+
+	return NewFieldError(name, err)
+}
+
+func joinPath(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+
+	return parent + "." + name
+}
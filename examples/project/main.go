@@ -0,0 +1,56 @@
+// Package main example on how to use gofieldselect.Project to transform a DAO object to a DTO with field selection.
+package main
+
+import (
+	"fmt"
+
+	"examples"
+
+	"github.com/golaxo/gofieldselect"
+)
+
+type (
+	userDao struct {
+		Name     string     `json:"name"`
+		LastName string     `json:"lastName"`
+		Age      int        `json:"age"`
+		Address  addressDao `json:"address"`
+	}
+
+	addressDao struct {
+		Street string `json:"street"`
+		Number int    `json:"number"`
+	}
+
+	userDto struct {
+		Name    string            `json:"name,omitempty"`
+		Surname string            `json:"surname,omitempty" fieldselect:"from=lastName"`
+		Age     int               `json:"age,omitempty"`
+		Address *examples.Address `json:"address,omitempty"`
+	}
+)
+
+func main() {
+	user := userDao{
+		Name:     "John",
+		LastName: "Doe",
+		Age:      18,
+		Address: addressDao{
+			Street: "Example",
+			Number: 1,
+		},
+	}
+
+	fieldSelection, err := gofieldselect.Parse("name,surname,address(street)")
+	if err != nil {
+		panic(err)
+	}
+
+	dto, err := gofieldselect.Project[userDao, userDto](fieldSelection, user)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("Source: \n %+v\n", examples.ToIndentedJson(user))
+	fmt.Printf("Selected: \n%+v\n", examples.ToIndentedJson(dto))
+}
@@ -0,0 +1,41 @@
+package gofieldselect
+
+import (
+	"slices"
+	"strings"
+)
+
+// ToFieldMaskPaths returns the paths of a protobuf FieldMask for the selection [n], e.g. `address.street`,
+// canonicalized: sorted, without duplicates and without the paths whose parent is already selected.
+// It returns nil, an empty mask, when every field is selected.
+// The fields with an empty child selection have no path, so `name,address()` is `name`, and a selection without
+// any field, e.g. `address()`, returns an empty but non-nil slice, which must not be sent as an empty mask since
+// that selects every field. The empty field names can't be part of a path and are skipped.
+func ToFieldMaskPaths(n Node) []string {
+	paths := nodePaths(n)
+	if paths == nil {
+		return nil
+	}
+
+	paths = slices.DeleteFunc(paths, func(path string) bool {
+		return slices.Contains(strings.Split(path, pathSeparator), "")
+	})
+	if len(paths) == 0 {
+		return []string{}
+	}
+
+	// merging the paths again removes the duplicated ones and the children of selected parents, the paths
+	// without empty names are always valid
+	merged, _ := nodeFromPaths(paths)
+
+	paths = nodePaths(merged)
+	slices.Sort(paths)
+
+	return paths
+}
+
+// FromFieldMaskPaths returns the selection of the paths of a protobuf FieldMask, every field for an empty mask.
+// A path with an empty field name, e.g. `address..street`, returns a [FieldError].
+func FromFieldMaskPaths(paths []string) (Node, error) {
+	return nodeFromPaths(paths)
+}
@@ -0,0 +1,72 @@
+package gofieldselect
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestToFieldMaskPaths(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string][]string{
+		"surname,name,address(street,number)": {"address.number", "address.street", "name", "surname"},
+		"address(street),address,name,name":   {"address", "name"},
+		"name,address()":                      {"name"},
+		"":                                    nil,
+	}
+
+	for sel, expected := range tests {
+		if got := ToFieldMaskPaths(parse(t, sel)); !slices.Equal(got, expected) {
+			t.Fatalf("%q: expected %v, got %v", sel, expected, got)
+		}
+	}
+
+	// no field is not an empty mask, which would select every field
+	for _, n := range []Node{parse(t, "address()"), Identifiers{}, Identifiers{{Value: "", Child: AllIdentifiers{}}}} {
+		if got := ToFieldMaskPaths(n); got == nil || len(got) != 0 {
+			t.Fatalf("%s: expected an empty non-nil slice, got %#v", canonical(n), got)
+		}
+	}
+
+	n := Identifiers{{Value: "name"}, {Value: "address", Child: Identifiers{{Value: ""}}}}
+	if got := ToFieldMaskPaths(n); !slices.Equal(got, []string{"name"}) {
+		t.Fatalf("expected the empty names to be skipped, got %v", got)
+	}
+}
+
+func TestFromFieldMaskPaths(t *testing.T) {
+	t.Parallel()
+
+	n, err := FromFieldMaskPaths([]string{"address.street", "name", "address.number", "friend.address.street"})
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	if got := canonical(n); got != "(address(number,street),friend(address(street)),name)" {
+		t.Fatalf("unexpected selection %s", got)
+	}
+
+	if n, err = FromFieldMaskPaths(nil); err != nil || !isAllIdentifiers(n) {
+		t.Fatalf("expected every field for an empty mask, got %v, %v", n, err)
+	}
+
+	if _, err = FromFieldMaskPaths([]string{"address..street"}); !errors.Is(err, ErrExpectedIdentifier) {
+		t.Fatalf("expected ErrExpectedIdentifier, got %v", err)
+	}
+}
+
+func TestFieldMaskRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	sel := "name,address(street,number),friend(name)"
+
+	n, err := FromFieldMaskPaths(ToFieldMaskPaths(parse(t, sel)))
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	if canonical(n) != canonical(parse(t, sel)) {
+		t.Fatalf("expected %s, got %s", canonical(parse(t, sel)), canonical(n))
+	}
+}
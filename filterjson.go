@@ -0,0 +1,252 @@
+package gofieldselect
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// jsonFilter copies JSON tokens from a decoder to a writer, skipping the subtrees not selected.
+type jsonFilter struct {
+	dec *json.Decoder
+	w   *bufio.Writer
+	// compacted is reused to compact and encode the values before writing them.
+	compacted bytes.Buffer
+}
+
+// FilterJSON returns the JSON document [in] with only the fields specified in [n], without unmarshalling it.
+// When the document is an array, the selection is applied to each element.
+// [in] must hold a single JSON value, use [FilterJSONStream] for several ones.
+func FilterJSON(n Node, in []byte) ([]byte, error) {
+	var out bytes.Buffer
+
+	count, err := filterJSONStream(n, bytes.NewReader(in), &out, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if count == 0 {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	return bytes.TrimSuffix(out.Bytes(), []byte{'\n'}), nil
+}
+
+// FilterJSONStream reads JSON values from [r] and writes them to [w] with only the fields specified in [n],
+// each one followed by a newline, so NDJSON streams are supported.
+// The values are filtered token by token, and the elements of top-level arrays one by one, so they are never
+// fully decoded into memory.
+func FilterJSONStream(n Node, r io.Reader, w io.Writer) error {
+	_, err := filterJSONStream(n, r, w, false)
+
+	return err
+}
+
+// filterJSONStream filters all the values in r and returns how many of them were written, failing with
+// [ErrMultipleJSONValues] if there is more than one and single is true.
+func filterJSONStream(n Node, r io.Reader, w io.Writer, single bool) (int, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	f := &jsonFilter{dec: dec, w: bufio.NewWriter(w)}
+
+	count := 0
+
+	for {
+		if single && count == 1 && dec.More() {
+			return count, ErrMultipleJSONValues
+		}
+
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return count, err
+		}
+
+		if err = f.filterToken(n, tok); err != nil {
+			return count, err
+		}
+
+		if err = f.w.WriteByte('\n'); err != nil {
+			return count, err
+		}
+
+		count++
+	}
+
+	return count, f.w.Flush()
+}
+
+// filterToken writes the value that starts with tok with only the fields in node.
+func (f *jsonFilter) filterToken(node Node, tok json.Token) error {
+	switch t := tok.(type) {
+	case json.Delim:
+		if t == '{' {
+			return f.filterObject(node)
+		}
+
+		return f.filterArray(node)
+	default:
+		return f.writeScalar(t)
+	}
+}
+
+// filterValue writes the next value of the decoder with only the fields in node, copying it as it is
+// when every field is selected.
+func (f *jsonFilter) filterValue(node Node) error {
+	if isAllIdentifiers(node) {
+		var raw json.RawMessage
+		if err := f.dec.Decode(&raw); err != nil {
+			return err
+		}
+
+		f.compacted.Reset()
+		if err := json.Compact(&f.compacted, raw); err != nil {
+			return err
+		}
+
+		_, err := f.w.Write(f.compacted.Bytes())
+
+		return err
+	}
+
+	tok, err := f.dec.Token()
+	if err != nil {
+		return err
+	}
+
+	return f.filterToken(node, tok)
+}
+
+// filterObject writes the selected members of the object whose '{' was already read.
+func (f *jsonFilter) filterObject(node Node) error {
+	if err := f.w.WriteByte('{'); err != nil {
+		return err
+	}
+
+	first := true
+
+	for f.dec.More() {
+		tok, err := f.dec.Token()
+		if err != nil {
+			return err
+		}
+
+		//nolint:errcheck // object keys are always strings
+		key := tok.(string)
+
+		ident, ok := node.SelectField(key)
+		if !ok {
+			if err = f.skipValue(); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if !first {
+			if err = f.w.WriteByte(','); err != nil {
+				return err
+			}
+		}
+
+		first = false
+
+		if err = f.writeScalar(key); err != nil {
+			return err
+		}
+
+		if err = f.w.WriteByte(':'); err != nil {
+			return err
+		}
+
+		if err = f.filterValue(childNode(ident)); err != nil {
+			return wrapFieldError(key, err)
+		}
+	}
+
+	// consume '}'
+	if _, err := f.dec.Token(); err != nil {
+		return err
+	}
+
+	return f.w.WriteByte('}')
+}
+
+// filterArray writes the elements of the array whose '[' was already read, applying node to each of them.
+func (f *jsonFilter) filterArray(node Node) error {
+	if err := f.w.WriteByte('['); err != nil {
+		return err
+	}
+
+	for i := 0; f.dec.More(); i++ {
+		if i > 0 {
+			if err := f.w.WriteByte(','); err != nil {
+				return err
+			}
+		}
+
+		if err := f.filterValue(node); err != nil {
+			return err
+		}
+	}
+
+	// consume ']'
+	if _, err := f.dec.Token(); err != nil {
+		return err
+	}
+
+	return f.w.WriteByte(']')
+}
+
+// skipValue reads the next value of the decoder without writing it.
+func (f *jsonFilter) skipValue() error {
+	depth := 0
+
+	for {
+		tok, err := f.dec.Token()
+		if err != nil {
+			return err
+		}
+
+		if d, ok := tok.(json.Delim); ok {
+			switch d {
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+			}
+		}
+
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+func (f *jsonFilter) writeScalar(v json.Token) error {
+	if n, ok := v.(json.Number); ok {
+		_, err := f.w.WriteString(n.String())
+
+		return err
+	}
+
+	// keep the strings as they are, without escaping HTML characters
+	f.compacted.Reset()
+
+	enc := json.NewEncoder(&f.compacted)
+	enc.SetEscapeHTML(false)
+
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+
+	_, err := f.w.Write(bytes.TrimSuffix(f.compacted.Bytes(), []byte{'\n'}))
+
+	return err
+}
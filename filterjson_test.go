@@ -0,0 +1,95 @@
+package gofieldselect
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestFilterJSON(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		selection string
+		input     string
+		expected  string
+	}{
+		"root fields": {
+			selection: "name,age",
+			input:     `{"name": "John", "surname": "Doe", "age": 0, "big": 12345678901234567890}`,
+			expected:  `{"name":"John","age":0}`,
+		},
+		"nested fields": {
+			selection: "address(street),tags",
+			input:     `{"address": {"street": "Main", "number": 1, "geo": {"lat": 1.5}}, "tags": ["a", {"b": [1, 2]}]}`,
+			expected:  `{"address":{"street":"Main"},"tags":["a",{"b":[1,2]}]}`,
+		},
+		"array elements": {
+			selection: "id,items(name)",
+			input:     `[{"id": 1, "x": {"y": []}, "items": [{"name": "a", "price": 1}]}, {"id": 2, "items": null}]`,
+			expected:  `[{"id":1,"items":[{"name":"a"}]},{"id":2,"items":null}]`,
+		},
+		"all fields": {
+			selection: "",
+			input:     `{"a": [1, 2], "b": {"c": "<d>"}}`,
+			expected:  `{"a":[1,2],"b":{"c":"<d>"}}`,
+		},
+		"scalar": {
+			selection: "name",
+			input:     `"<value>"`,
+			expected:  `"<value>"`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := FilterJSON(parse(t, tc.selection), []byte(tc.input))
+			if err != nil {
+				t.Fatalf("error: %v", err)
+			}
+
+			if string(got) != tc.expected {
+				t.Fatalf("expected %s; got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestFilterJSONInvalid(t *testing.T) {
+	t.Parallel()
+
+	for _, input := range []string{``, `{"name": `, `{"name": "a",}`} {
+		if _, err := FilterJSON(parse(t, "name"), []byte(input)); err == nil {
+			t.Fatalf("expected error for %q", input)
+		}
+	}
+
+	for _, input := range []string{`{} {}`, `[] 1`, "{}\n{\"name\": 1}"} {
+		if _, err := FilterJSON(parse(t, "name"), []byte(input)); !errors.Is(err, ErrMultipleJSONValues) {
+			t.Fatalf("expected ErrMultipleJSONValues for %q, got %v", input, err)
+		}
+	}
+
+	if got, err := FilterJSON(parse(t, "name"), []byte(" {\"name\": 1}\n ")); err != nil || string(got) != `{"name":1}` {
+		t.Fatalf("expected the trailing whitespace to be allowed, got %s, %v", got, err)
+	}
+}
+
+func TestFilterJSONStreamNDJSON(t *testing.T) {
+	t.Parallel()
+
+	input := "{\"name\": \"John\", \"age\": 20}\n{\"name\": \"Jane\", \"age\": 30}\n"
+
+	var out bytes.Buffer
+	if err := FilterJSONStream(parse(t, "name"), strings.NewReader(input), &out); err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	expected := "{\"name\":\"John\"}\n{\"name\":\"Jane\"}\n"
+	if out.String() != expected {
+		t.Fatalf("expected %q; got %q", expected, out.String())
+	}
+}
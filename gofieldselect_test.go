@@ -2,6 +2,7 @@ package gofieldselect
 
 import (
 	"errors"
+	"reflect"
 	"testing"
 
 	"github.com/golaxo/gofieldselect/internal/lexer"
@@ -187,6 +188,109 @@ func TestApplyFromNodeNonStructError(t *testing.T) {
 	}
 }
 
+func TestApplyFromNodeDeepCopy(t *testing.T) {
+	t.Parallel()
+
+	type Order struct {
+		Tags    []string          `json:"tags"`
+		Labels  map[string]string `json:"labels"`
+		Address *Address          `json:"address"`
+		Items   []*Address        `json:"items"`
+	}
+
+	nodes := parse(t, "tags,labels,address,items(street)")
+	src := Order{
+		Tags:    []string{"a", "b"},
+		Labels:  map[string]string{"env": "prod"},
+		Address: &Address{Street: "Main", Number: 1},
+		Items:   []*Address{{Street: "Second", Number: 2}},
+	}
+
+	got, err := GetWithReflection(nodes, src, WithDeepCopy())
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	got.Tags[0] = "changed"
+	got.Labels["env"] = "changed"
+	got.Address.Street = "changed"
+	got.Items[0].Street = "changed"
+
+	if src.Tags[0] != "a" || src.Labels["env"] != "prod" || src.Address.Street != "Main" || src.Items[0].Street != "Second" {
+		t.Fatalf("source was modified through the copy: %+v", src)
+	}
+
+	if got.Items[0].Number != 0 {
+		t.Fatalf("expected items number not to be selected; got %d", got.Items[0].Number)
+	}
+}
+
+func TestApplyFromNodeDeepCopyCycle(t *testing.T) {
+	t.Parallel()
+
+	type Person struct {
+		Name   string  `json:"name"`
+		Friend *Person `json:"friend"`
+	}
+
+	src := &Person{Name: "John"}
+	src.Friend = &Person{Name: "Jane", Friend: src}
+
+	got, err := GetWithReflection(parse(t, ""), src, WithDeepCopy())
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	if got == src || got.Friend == src.Friend {
+		t.Fatalf("expected pointers to be duplicated")
+	}
+
+	if got.Friend.Friend != got {
+		t.Fatalf("expected the cycle to be kept in the copy")
+	}
+
+	if got.Friend.Name != "Jane" {
+		t.Fatalf("expected friend name Jane; got %q", got.Friend.Name)
+	}
+}
+
+func TestApplyFromNodeDeepCopyMapAndSliceCycle(t *testing.T) {
+	t.Parallel()
+
+	type Graph struct {
+		Nodes map[string]any `json:"nodes"`
+		Edges []any          `json:"edges"`
+	}
+
+	nodes := map[string]any{"name": "root"}
+	nodes["self"] = nodes
+
+	edges := make([]any, 2)
+	edges[0] = "a"
+	edges[1] = edges
+
+	src := Graph{Nodes: nodes, Edges: edges}
+
+	got, err := GetWithReflection(parse(t, ""), src, WithDeepCopy())
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	self, _ := got.Nodes["self"].(map[string]any)
+	if self["name"] != "root" || reflect.ValueOf(self).Pointer() != reflect.ValueOf(got.Nodes).Pointer() {
+		t.Fatalf("expected the map cycle to be kept in the copy")
+	}
+
+	if reflect.ValueOf(got.Nodes).Pointer() == reflect.ValueOf(src.Nodes).Pointer() {
+		t.Fatalf("expected the map to be duplicated")
+	}
+
+	inner, _ := got.Edges[1].([]any)
+	if len(inner) != 2 || &inner[0] != &got.Edges[0] || &got.Edges[0] == &src.Edges[0] {
+		t.Fatalf("expected the slice cycle to be kept in a duplicated slice")
+	}
+}
+
 // Helper to parse a selection string into nodes.
 func parse(t *testing.T, sel string) Node {
 	t.Helper()
@@ -0,0 +1,164 @@
+package gofieldselect
+
+import (
+	"strings"
+
+	"github.com/golaxo/gofieldselect/internal/token"
+)
+
+const (
+	// graphQLFragment and graphQLDirective start the fragment spreads, inline fragments and directives, not
+	// supported since they depend on the schema or the variables, e.g. `...UserFields` or `@include(if: $all)`.
+	graphQLFragment  = "..."
+	graphQLDirective = "@"
+)
+
+// parseGraphQL parses a GraphQL selection set, with or without its enclosing braces, e.g. `{ name address { street } }`.
+func (p *parser) parseGraphQL() Node {
+	if p.curToken.Type == token.EOF {
+		return AllIdentifiers{}
+	}
+
+	if p.curToken.Type != token.Lbrace {
+		n := p.parseSelectionSet()
+		p.expectEOF()
+
+		return n
+	}
+
+	p.nextToken() // move inside '{'
+
+	n := p.parseSelectionSet()
+
+	if p.curToken.Type == token.Rbrace {
+		p.nextToken()
+		p.expectEOF()
+	} else {
+		p.errors = append(p.errors, ErrExpectedClosingBrace)
+	}
+
+	return n
+}
+
+// parseSelectionSet parses the selections until a right brace or EOF, merging the ones of the same field, e.g. the
+// ones of several aliases.
+// It assumes p.curToken is positioned at the first token of the set, and an empty set is an error like in GraphQL.
+func (p *parser) parseSelectionSet() Node {
+	var n Node = Identifiers{}
+
+	empty := true
+
+	for p.curToken.Type != token.EOF && p.curToken.Type != token.Rbrace {
+		if p.curToken.Type != token.Ident {
+			// unexpected token, like arguments or a misplaced colon; skip it to keep progress
+			p.errors = append(p.errors, ErrExpectedIdentifier)
+			p.nextToken()
+
+			continue
+		}
+
+		ident, ok := p.parseSelection()
+		if ok {
+			n = mergeNodes(n, Identifiers{ident})
+		}
+
+		empty = false
+	}
+
+	if empty {
+		p.errors = append(p.errors, ErrExpectedIdentifier)
+	}
+
+	return n
+}
+
+// parseSelection parses a single field with an optional alias and selection set, e.g. `home: address { street }`.
+// The alias only names the field in a GraphQL response, so the selection is the one of the aliased field.
+// Precondition: p.curToken.Type == token.Ident
+// Postcondition: p.curToken will be the token following the selection.
+func (p *parser) parseSelection() (Identifier, bool) {
+	if !p.checkGraphQLName() {
+		p.nextToken()
+
+		return Identifier{}, false
+	}
+
+	if p.peekToken.Type == token.Colon {
+		p.nextToken() // move to ':'
+		p.nextToken() // move to the aliased field
+
+		if p.curToken.Type != token.Ident {
+			p.errors = append(p.errors, ErrExpectedIdentifier)
+
+			return Identifier{}, false
+		}
+
+		if !p.checkGraphQLName() {
+			p.nextToken()
+
+			return Identifier{}, false
+		}
+	}
+
+	ident := Identifier{Value: p.curToken.Literal, Child: AllIdentifiers{}}
+	p.nextToken()
+
+	if p.curToken.Type != token.Lbrace {
+		return ident, true
+	}
+
+	p.nextToken() // move inside '{'
+
+	ident.Child = p.parseSelectionSet()
+
+	if p.curToken.Type == token.Rbrace {
+		p.nextToken()
+	} else {
+		p.errors = append(p.errors, ErrExpectedClosingBrace)
+	}
+
+	return ident, true
+}
+
+// checkGraphQLName reports the fragments and directives, which start like a field name, e.g. `...UserFields`.
+func (p *parser) checkGraphQLName() bool {
+	switch {
+	case strings.HasPrefix(p.curToken.Literal, graphQLFragment):
+		p.errors = append(p.errors, ErrUnsupportedFragment)
+	case strings.HasPrefix(p.curToken.Literal, graphQLDirective):
+		p.errors = append(p.errors, ErrUnsupportedDirective)
+	default:
+		return true
+	}
+
+	return false
+}
+
+// expectEOF reports the tokens after the selection set, e.g. an extra closing brace.
+func (p *parser) expectEOF() {
+	if p.curToken.Type != token.EOF {
+		p.errors = append(p.errors, ErrUnexpectedToken)
+	}
+}
+
+// formatGraphQL writes the selection set of n, e.g. `{ name address { street } }`.
+func formatGraphQL(sb *strings.Builder, n Node) {
+	identifiers, ok := n.(Identifiers)
+	if !ok {
+		return
+	}
+
+	sb.WriteByte('{')
+
+	for _, ident := range identifiers {
+		sb.WriteByte(' ')
+		sb.WriteString(ident.Value)
+
+		if child := childNode(ident); !isAllIdentifiers(child) {
+			sb.WriteByte(' ')
+			formatGraphQL(sb, child)
+		}
+	}
+
+	sb.WriteString(" }")
+}
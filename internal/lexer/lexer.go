@@ -14,6 +14,10 @@ type Lexer struct {
 	readPosition int
 	// current character under examination
 	ch byte
+	// paths is true when `/` and `*` are tokens instead of identifier characters
+	paths bool
+	// graphQL is true when `{`, `}` and `:` are tokens, commas and new lines are whitespace and `#` starts a comment
+	graphQL bool
 }
 
 // New creates a new Lexer.
@@ -27,6 +31,24 @@ func New(input string) *Lexer {
 	return l
 }
 
+// NewWithPaths creates a new Lexer where `/` separates the fields of a path and `*` is a wildcard,
+// e.g. `items/id,items/*`.
+func NewWithPaths(input string) *Lexer {
+	l := New(input)
+	l.paths = true
+
+	return l
+}
+
+// NewGraphQL creates a new Lexer for GraphQL selection sets, e.g. `{ name address { street } }`, where commas are
+// ignored like whitespace and `#` comments until the end of the line.
+func NewGraphQL(input string) *Lexer {
+	l := New(input)
+	l.graphQL = true
+
+	return l
+}
+
 // NextToken returns the next token from the input stream.
 func (l *Lexer) NextToken() token.Token {
 	var tok token.Token
@@ -40,6 +62,26 @@ func (l *Lexer) NextToken() token.Token {
 		tok = newToken(token.Lparen, l.ch)
 	case ')':
 		tok = newToken(token.Rparen, l.ch)
+	case '/', '*':
+		if !l.paths {
+			return l.readIdentToken()
+		}
+
+		tok = newToken(token.Slash, l.ch)
+		if l.ch == '*' {
+			tok = newToken(token.Wildcard, l.ch)
+		}
+	case '{', '}', ':':
+		if !l.graphQL {
+			return l.readIdentToken()
+		}
+
+		tok = newToken(token.Lbrace, l.ch)
+		if l.ch == '}' {
+			tok = newToken(token.Rbrace, l.ch)
+		} else if l.ch == ':' {
+			tok = newToken(token.Colon, l.ch)
+		}
 	case '\n', '\t', '\r':
 		tok = newToken(token.Illegal, l.ch)
 	case 0:
@@ -47,12 +89,8 @@ func (l *Lexer) NextToken() token.Token {
 		tok.Literal = ""
 	default:
 		// Ident: any JSON key characters until delimiter or whitespace
-		if isIdentChar(l.ch) {
-			literal := l.readIdentifier()
-			tok.Type = token.Ident
-			tok.Literal = literal
-
-			return tok
+		if l.isIdentChar(l.ch) {
+			return l.readIdentToken()
 		}
 
 		tok = newToken(token.Illegal, l.ch)
@@ -79,32 +117,42 @@ func (l *Lexer) readChar() {
 }
 
 func (l *Lexer) skipWhitespace() {
-	for isWhitespace(l.ch) {
-		l.readChar()
+	for {
+		switch {
+		case l.isWhitespace(l.ch):
+			l.readChar()
+		case l.graphQL && l.ch == '#':
+			for l.ch != '\n' && l.ch != 0 {
+				l.readChar()
+			}
+		default:
+			return
+		}
 	}
 }
 
-func (l *Lexer) readIdentifier() string {
+func (l *Lexer) readIdentToken() token.Token {
 	start := l.position
-	for isIdentChar(l.ch) {
+	for l.isIdentChar(l.ch) {
 		l.readChar()
 	}
 
-	return l.input[start:l.position]
+	return token.Token{Type: token.Ident, Literal: l.input[start:l.position]}
 }
 
 // isWhitespace reports whether the given byte is a whitespace we should skip between tokens.
-func isWhitespace(ch byte) bool {
-	return ch == ' '
+func (l *Lexer) isWhitespace(ch byte) bool {
+	return ch == ' ' || (l.graphQL && (ch == '\n' || ch == '\t' || ch == '\r' || ch == ','))
 }
 
 // isDelimiter is any character that separates tokens and is not part of an identifier.
-func isDelimiter(ch byte) bool {
-	return ch == ',' || ch == '(' || ch == ')' || ch == 0
+func (l *Lexer) isDelimiter(ch byte) bool {
+	return ch == ',' || ch == '(' || ch == ')' || ch == 0 || (l.paths && (ch == '/' || ch == '*')) ||
+		(l.graphQL && (ch == '{' || ch == '}' || ch == ':' || ch == '#'))
 }
 
 // isIdentChar reports whether the byte can be part of an identifier (JSON key) in this grammar.
 // We allow any non-delimiter, non-whitespace character sequence.
-func isIdentChar(ch byte) bool {
-	return !isDelimiter(ch) && !isWhitespace(ch)
+func (l *Lexer) isIdentChar(ch byte) bool {
+	return !l.isDelimiter(ch) && !l.isWhitespace(ch)
 }
@@ -125,3 +125,58 @@ func TestNextTokenTab(t *testing.T) {
 		}
 	}
 }
+
+func TestNextTokenPaths(t *testing.T) {
+	t.Parallel()
+
+	input := "items/id,etag/*"
+
+	expected := []token.Token{
+		{Type: token.Ident, Literal: "items"},
+		{Type: token.Slash, Literal: "/"},
+		{Type: token.Ident, Literal: "id"},
+		{Type: token.Separator, Literal: ","},
+		{Type: token.Ident, Literal: "etag"},
+		{Type: token.Slash, Literal: "/"},
+		{Type: token.Wildcard, Literal: "*"},
+		{Type: token.EOF, Literal: ""},
+	}
+
+	l := NewWithPaths(input)
+
+	for i, tt := range expected {
+		tok := l.NextToken()
+
+		if tok.Type != tt.Type || tok.Literal != tt.Literal {
+			t.Fatalf("tests[%d] - expected=%v, got=%v", i, tt, tok)
+		}
+	}
+}
+
+func TestNextTokenGraphQL(t *testing.T) {
+	t.Parallel()
+
+	input := "{ home: address, # where\n\t{ street } }"
+
+	expected := []token.Token{
+		{Type: token.Lbrace, Literal: "{"},
+		{Type: token.Ident, Literal: "home"},
+		{Type: token.Colon, Literal: ":"},
+		{Type: token.Ident, Literal: "address"},
+		{Type: token.Lbrace, Literal: "{"},
+		{Type: token.Ident, Literal: "street"},
+		{Type: token.Rbrace, Literal: "}"},
+		{Type: token.Rbrace, Literal: "}"},
+		{Type: token.EOF, Literal: ""},
+	}
+
+	l := NewGraphQL(input)
+
+	for i, tt := range expected {
+		tok := l.NextToken()
+
+		if tok.Type != tt.Type || tok.Literal != tt.Literal {
+			t.Fatalf("tests[%d] - expected=%v, got=%v", i, tt, tok)
+		}
+	}
+}
@@ -14,6 +14,17 @@ const (
 
 	Lparen Type = "("
 	Rparen Type = ")"
+
+	// Slash path separator, only in the paths dialect, e.g. `items/id`.
+	Slash Type = "/"
+	// Wildcard every field, only in the paths dialect, e.g. `items/*`.
+	Wildcard Type = "*"
+
+	// Lbrace and Rbrace enclose a selection set, only in the GraphQL dialect, e.g. `{ name }`.
+	Lbrace Type = "{"
+	Rbrace Type = "}"
+	// Colon separates an alias from its field, only in the GraphQL dialect, e.g. `fullName: name`.
+	Colon Type = ":"
 )
 
 type (
@@ -0,0 +1,215 @@
+package gofieldselect
+
+import (
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+const (
+	// jsonAPIFieldsPrefix and jsonAPIFieldsSuffix enclose the resource type of a sparse fieldset,
+	// e.g. `fields[articles]`.
+	jsonAPIFieldsPrefix = "fields["
+	jsonAPIFieldsSuffix = "]"
+	jsonAPIInclude      = "include"
+
+	// jsonAPIType and jsonAPIID are the members identifying a resource, always kept.
+	jsonAPIType          = "type"
+	jsonAPIID            = "id"
+	jsonAPIAttributes    = "attributes"
+	jsonAPIRelationships = "relationships"
+)
+
+// JSONAPIQuery is the selection of a JSON:API request, its sparse fieldsets and the included relationships.
+type JSONAPIQuery struct {
+	// Fields is the selection of each resource type, e.g. `title,body` for `fields[articles]=title,body`.
+	Fields map[string]Node
+	// Include is the selection of the relationship paths to include, e.g. `author(employer)` for
+	// `include=author.employer`, nil when there isn't an `include` parameter.
+	Include Node
+}
+
+// ParseJSONAPIQuery returns the sparse fieldsets, `fields[articles]=title,body`, and the included relationships,
+// `include=author,comments.author`, of the JSON:API query parameters.
+// An empty fieldset selects no fields, and since fieldsets are flat member names, a nested one, e.g. `author(name)`,
+// returns [ErrNestedSelection]. The errors are [FieldError] with the name of the parameter, the first one in
+// sorted order when there are several.
+func ParseJSONAPIQuery(query url.Values) (JSONAPIQuery, error) {
+	q := JSONAPIQuery{Fields: make(map[string]Node)}
+
+	for _, key := range slices.Sorted(maps.Keys(query)) {
+		values := query[key]
+
+		resourceType, ok := strings.CutPrefix(key, jsonAPIFieldsPrefix)
+		if !ok {
+			continue
+		}
+
+		resourceType, ok = strings.CutSuffix(resourceType, jsonAPIFieldsSuffix)
+		if !ok || resourceType == "" {
+			continue
+		}
+
+		n, err := parseJSONAPIFieldset(strings.Join(values, ","))
+		if err != nil {
+			return JSONAPIQuery{}, wrapFieldError(key, err)
+		}
+
+		q.Fields[resourceType] = n
+	}
+
+	if values, ok := query[jsonAPIInclude]; ok {
+		var paths []string
+
+		for _, v := range values {
+			for p := range strings.SplitSeq(v, ",") {
+				if p = strings.TrimSpace(p); p != "" {
+					paths = append(paths, p)
+				}
+			}
+		}
+
+		if len(paths) == 0 {
+			q.Include = Identifiers{}
+		} else {
+			n, err := nodeFromPaths(paths)
+			if err != nil {
+				return JSONAPIQuery{}, wrapFieldError(jsonAPIInclude, err)
+			}
+
+			q.Include = n
+		}
+	}
+
+	return q, nil
+}
+
+// For returns the selection of the resource type, every field when there isn't a fieldset for it.
+func (q JSONAPIQuery) For(resourceType string) Node {
+	if n, ok := q.Fields[resourceType]; ok {
+		return n
+	}
+
+	return AllIdentifiers{}
+}
+
+// Includes reports whether the dot separated relationship path is included, e.g. `comments.author`, so are its
+// parents, e.g. `comments`.
+func (q JSONAPIQuery) Includes(path string) bool {
+	n := q.Include
+	if n == nil {
+		return false
+	}
+
+	for name := range strings.SplitSeq(path, pathSeparator) {
+		if isAllIdentifiers(n) {
+			return false
+		}
+
+		ident, ok := n.SelectField(name)
+		if !ok {
+			return false
+		}
+
+		n = childNode(ident)
+	}
+
+	return true
+}
+
+// Project returns the resources with only the fields of the fieldset of their `type` member, keeping `type` and
+// `id`.
+// The resources can be JSON:API resource objects, whose `attributes` and `relationships` members are filtered, or
+// flat structs and maps, see [ToMap], that are filtered as a whole.
+// A resource without a `type` returns a [FieldError] with [ErrMissingResourceType].
+func (q JSONAPIQuery) Project(resources ...any) ([]map[string]any, error) {
+	projected := make([]map[string]any, len(resources))
+
+	for i, r := range resources {
+		m, err := ToMap(AllIdentifiers{}, r)
+		if err != nil {
+			return nil, err
+		}
+
+		resourceType, ok := m[jsonAPIType].(string)
+		if !ok || resourceType == "" {
+			return nil, NewFieldError(jsonAPIType, ErrMissingResourceType)
+		}
+
+		if projected[i], err = q.projectResource(q.For(resourceType), m); err != nil {
+			return nil, wrapFieldError(resourceType, err)
+		}
+	}
+
+	return projected, nil
+}
+
+// projectResource returns the resource object with only the attributes and relationships in n, or only the members
+// in n when it's a flat resource.
+func (q JSONAPIQuery) projectResource(n Node, resource map[string]any) (map[string]any, error) {
+	_, hasAttributes := resource[jsonAPIAttributes]
+	_, hasRelationships := resource[jsonAPIRelationships]
+
+	if !hasAttributes && !hasRelationships {
+		res, err := ToMap(n, resource)
+		if err != nil {
+			return nil, err
+		}
+
+		res[jsonAPIType] = resource[jsonAPIType]
+		if id, ok := resource[jsonAPIID]; ok {
+			res[jsonAPIID] = id
+		}
+
+		return res, nil
+	}
+
+	res := make(map[string]any, len(resource))
+
+	for key, v := range resource {
+		if key != jsonAPIAttributes && key != jsonAPIRelationships {
+			res[key] = v
+
+			continue
+		}
+
+		members, ok := v.(map[string]any)
+		if !ok {
+			res[key] = v
+
+			continue
+		}
+
+		filtered, err := ToMap(n, members)
+		if err != nil {
+			return nil, wrapFieldError(key, err)
+		}
+
+		res[key] = filtered
+	}
+
+	return res, nil
+}
+
+// parseJSONAPIFieldset returns the selection of the fields of a fieldset, none when it's empty.
+func parseJSONAPIFieldset(fieldset string) (Node, error) {
+	if strings.Trim(fieldset, ", ") == "" {
+		return Identifiers{}, nil
+	}
+
+	n, err := Parse(fieldset)
+	if err != nil {
+		return nil, err
+	}
+
+	// a non-empty fieldset is always a list of identifiers
+	identifiers, _ := n.(Identifiers)
+	for _, ident := range identifiers {
+		if !isAllIdentifiers(childNode(ident)) {
+			return nil, NewFieldError(ident.Value, ErrNestedSelection)
+		}
+	}
+
+	return n, nil
+}
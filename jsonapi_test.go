@@ -0,0 +1,112 @@
+package gofieldselect
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func TestParseJSONAPIQuery(t *testing.T) {
+	t.Parallel()
+
+	query, err := url.ParseQuery("fields[articles]=title,body&fields[people]=name&fields[tags]=&include=author,comments.author")
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	q, err := ParseJSONAPIQuery(query)
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	tests := map[string]string{
+		"articles": "(body,title)",
+		"people":   "(name)",
+		"tags":     "()",
+		"comments": "*",
+	}
+
+	for resourceType, expected := range tests {
+		if got := canonical(q.For(resourceType)); got != expected {
+			t.Fatalf("%s: expected %s, got %s", resourceType, expected, got)
+		}
+	}
+
+	for path, expected := range map[string]bool{
+		"author": true, "comments": true, "comments.author": true, "comments.article": false, "tags": false,
+	} {
+		if got := q.Includes(path); got != expected {
+			t.Fatalf("%s: expected included %t, got %t", path, expected, got)
+		}
+	}
+
+	if q, err = ParseJSONAPIQuery(url.Values{}); err != nil || q.Include != nil || q.Includes("author") {
+		t.Fatalf("expected no includes, got %v, %v", q.Include, err)
+	}
+
+	_, err = ParseJSONAPIQuery(url.Values{"fields[articles]": {"title,,body"}})
+
+	var fe FieldError
+	if !errors.As(err, &fe) || fe.Path() != "fields[articles]" {
+		t.Fatalf("expected a FieldError for the fieldset, got %v", err)
+	}
+
+	_, err = ParseJSONAPIQuery(url.Values{"fields[articles]": {"title,author(name)"}})
+	if !errors.Is(err, ErrNestedSelection) || !errors.As(err, &fe) || fe.Path() != "fields[articles].author" {
+		t.Fatalf("expected ErrNestedSelection for the nested member, got %v", err)
+	}
+
+	// the first invalid fieldset in sorted order is reported
+	invalid := url.Values{"fields[people]": {"a(b)"}, "fields[articles]": {"c(d)"}, "fields[tags]": {"e(f)"}}
+	for range 10 {
+		if _, err = ParseJSONAPIQuery(invalid); !errors.As(err, &fe) || fe.Path() != "fields[articles].c" {
+			t.Fatalf("expected the error of fields[articles], got %v", err)
+		}
+	}
+}
+
+func TestJSONAPIQueryProject(t *testing.T) {
+	t.Parallel()
+
+	type person struct {
+		Type string `json:"type"`
+		ID   string `json:"id"`
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+
+	q, err := ParseJSONAPIQuery(url.Values{"fields[articles]": {"title,author"}, "fields[people]": {"name"}})
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	article := map[string]any{
+		"type":          "articles",
+		"id":            "1",
+		"attributes":    map[string]any{"title": "JSON:API", "body": "..."},
+		"relationships": map[string]any{"author": map[string]any{"data": map[string]any{"type": "people", "id": "9"}}},
+		"links":         map[string]any{"self": "/articles/1"},
+	}
+
+	got, err := q.Project(article, person{Type: "people", ID: "9", Name: "Dan", Age: 30})
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	attributes, _ := got[0]["attributes"].(map[string]any)
+	if len(attributes) != 1 || attributes["title"] != "JSON:API" || got[0]["links"] == nil || got[0]["id"] != "1" {
+		t.Fatalf("unexpected article %v", got[0])
+	}
+
+	if relationships, _ := got[0]["relationships"].(map[string]any); relationships["author"] == nil {
+		t.Fatalf("expected the author relationship, got %v", got[0])
+	}
+
+	if len(got[1]) != 3 || got[1]["type"] != "people" || got[1]["id"] != "9" || got[1]["name"] != "Dan" {
+		t.Fatalf("unexpected person %v", got[1])
+	}
+
+	if _, err = q.Project(map[string]any{"id": "1"}); !errors.Is(err, ErrMissingResourceType) {
+		t.Fatalf("expected ErrMissingResourceType, got %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+package gofieldselect
+
+import (
+	"database/sql"
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+//nolint:gochecknoglobals // registry of the types that are always copied whole
+var leafTypes = struct {
+	sync.RWMutex
+	types map[reflect.Type]struct{}
+}{
+	types: map[reflect.Type]struct{}{
+		reflect.TypeFor[time.Time]():       {},
+		reflect.TypeFor[json.RawMessage](): {},
+		reflect.TypeFor[sql.NullString]():  {},
+		reflect.TypeFor[sql.NullInt64]():   {},
+		reflect.TypeFor[sql.NullInt32]():   {},
+		reflect.TypeFor[sql.NullInt16]():   {},
+		reflect.TypeFor[sql.NullByte]():    {},
+		reflect.TypeFor[sql.NullFloat64](): {},
+		reflect.TypeFor[sql.NullBool]():    {},
+		reflect.TypeFor[sql.NullTime]():    {},
+	},
+}
+
+//nolint:gochecknoglobals // cache of the leaf check, reset when a new leaf type is registered
+var leafCache sync.Map // map[reflect.Type]bool
+
+//nolint:gochecknoglobals // interfaces whose implementations are always copied whole
+var leafInterfaces = []reflect.Type{
+	reflect.TypeFor[json.Marshaler](),
+	reflect.TypeFor[encoding.TextMarshaler](),
+}
+
+// RegisterLeafType registers [T] as a leaf type, its values are always copied whole and a child selection on them
+// is not valid.
+// [time.Time], [json.RawMessage], the [sql] Null types and any type implementing [json.Marshaler] or
+// [encoding.TextMarshaler] are already leaf types.
+func RegisterLeafType[T any]() {
+	leafTypes.Lock()
+	defer leafTypes.Unlock()
+
+	leafTypes.types[reflect.TypeFor[T]()] = struct{}{}
+
+	// the leaf-ness of the fields is part of the plans and the projected types
+	leafCache.Clear()
+	plans.Clear()
+	restrictedTypes.Clear()
+	projectedTypes.Clear()
+}
+
+// isLeafType reports whether the values of the type must be treated as a whole instead of going through its fields.
+// Pointers and interfaces are never leaves, the value they hold is checked instead.
+func isLeafType(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr || t.Kind() == reflect.Interface {
+		return false
+	}
+
+	if leaf, ok := leafCache.Load(t); ok {
+		//nolint:errcheck // it's always a bool
+		return leaf.(bool)
+	}
+
+	leaf := computeIsLeafType(t)
+	leafCache.Store(t, leaf)
+
+	return leaf
+}
+
+func computeIsLeafType(t reflect.Type) bool {
+	leafTypes.RLock()
+	_, ok := leafTypes.types[t]
+	leafTypes.RUnlock()
+
+	if ok {
+		return true
+	}
+
+	// sql.Null[T] is generic, so it cannot be registered for every T
+	if t.PkgPath() == "database/sql" && strings.HasPrefix(t.Name(), "Null[") {
+		return true
+	}
+
+	for _, i := range leafInterfaces {
+		if t.Implements(i) || reflect.PointerTo(t).Implements(i) {
+			return true
+		}
+	}
+
+	return false
+}
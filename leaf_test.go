@@ -0,0 +1,104 @@
+package gofieldselect
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type Event struct {
+	Name      string         `json:"name"`
+	CreatedAt time.Time      `json:"createdAt"`
+	UpdatedAt *time.Time     `json:"updatedAt"`
+	Comment   sql.NullString `json:"comment"`
+}
+
+func TestGetWithReflectionLeafTypeCopiedWhole(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	src := Event{
+		Name:      "launch",
+		CreatedAt: now,
+		UpdatedAt: &now,
+		Comment:   sql.NullString{String: "ok", Valid: true},
+	}
+
+	got, err := GetWithReflection(parse(t, "createdAt,updatedAt,comment"), src, WithDeepCopy())
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	if !got.CreatedAt.Equal(now) || !got.UpdatedAt.Equal(now) {
+		t.Fatalf("expected times to be copied; got %+v", got)
+	}
+
+	if got.UpdatedAt == src.UpdatedAt {
+		t.Fatalf("expected updatedAt pointer to be duplicated")
+	}
+
+	if got.Comment != src.Comment {
+		t.Fatalf("expected comment %+v; got %+v", src.Comment, got.Comment)
+	}
+}
+
+func TestGetWithReflectionChildSelectionOnLeaf(t *testing.T) {
+	t.Parallel()
+
+	_, err := GetWithReflection(parse(t, "createdAt(foo)"), Event{CreatedAt: time.Now()})
+	if !errors.Is(err, ErrChildSelectionOnLeaf) {
+		t.Fatalf("expected ErrChildSelectionOnLeaf; got %v", err)
+	}
+
+	var fe FieldError
+	if !errors.As(err, &fe) || fe.Path() != "createdAt" {
+		t.Fatalf("expected field error for createdAt; got %v", err)
+	}
+}
+
+func TestRegisterLeafType(t *testing.T) {
+	t.Parallel()
+
+	type Money struct {
+		Amount   int    `json:"amount"`
+		Currency string `json:"currency"`
+	}
+
+	type Invoice struct {
+		Total Money `json:"total"`
+	}
+
+	RegisterLeafType[Money]()
+
+	err := Validate[Invoice](parse(t, "total(amount)"))
+	if !errors.Is(err, ErrChildSelectionOnLeaf) {
+		t.Fatalf("expected ErrChildSelectionOnLeaf; got %v", err)
+	}
+}
+
+func TestRegisterLeafTypeProjectedTypes(t *testing.T) {
+	t.Parallel()
+
+	type Coordinates struct {
+		Lat    float64 `json:"lat"`
+		Lng    float64 `json:"lng"`
+		Source string  `json:"source" fieldselect:"never"`
+	}
+
+	type Place struct {
+		Name     string      `json:"name"`
+		Location Coordinates `json:"location"`
+	}
+
+	if pt := ProjectType[Place](parse(t, "")); pt.Field(1).Type.NumField() != 2 {
+		t.Fatalf("expected the projected coordinates, got %v", pt)
+	}
+
+	RegisterLeafType[Coordinates]()
+
+	if pt := ProjectType[Place](parse(t, "")); pt != reflect.TypeFor[Place]() {
+		t.Fatalf("expected the original type once the coordinates are a leaf, got %v", pt)
+	}
+}
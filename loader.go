@@ -0,0 +1,300 @@
+package gofieldselect
+
+import (
+	"context"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+type (
+	// Loader loads in a single batch the values of a relation field for every struct that has it, see [NewLoader]
+	// for a typed one.
+	Loader interface {
+		// Key returns the key of the relation in parent, the struct value that has the relation field.
+		// The keys must be comparable, the equal ones are loaded once.
+		Key(parent any) (any, error)
+		// Load returns the values of the keys, the missing ones leave the relation field with its zero value.
+		Load(ctx context.Context, keys []any) (map[any]any, error)
+	}
+
+	// Loaders holds the loaders of the relations by their field path, e.g. `customer` or `lines.product`.
+	Loaders map[string]Loader
+
+	batchLoader[T any, K comparable, V any] struct {
+		key   func(T) K
+		batch func(ctx context.Context, keys []K) (map[K]V, error)
+	}
+
+	// loadTarget is a relation field to set with the value loaded for its key.
+	loadTarget struct {
+		key  any
+		node Node
+		dst  reflect.Value
+	}
+
+	// loadRoot is a value, and its projection, where the relations of a loader path are looked for.
+	loadRoot struct {
+		node Node
+		src  reflect.Value
+		dst  reflect.Value
+	}
+
+	// loadCollector finds the relation fields of a loader path in a value and its projection.
+	loadCollector struct {
+		opts    options
+		targets []loadTarget
+		// loaded holds the loaded values and their projections, where the nested relations are looked for.
+		loaded []loadRoot
+		err    error
+	}
+)
+
+// NewLoader creates a [Loader] for a relation field of the struct [T], where key returns the key of the relation
+// of a [T] value, e.g. the customer ID of an order, and load returns the values of all the keys at once.
+// The keys without a value leave the relation field with its zero value.
+func NewLoader[T any, K comparable, V any](
+	key func(T) K, load func(ctx context.Context, keys []K) (map[K]V, error),
+) Loader {
+	return batchLoader[T, K, V]{key: key, batch: load}
+}
+
+// Key returns the key of the [T] parent, [ErrIncompatibleTypes] for other types.
+func (bl batchLoader[T, K, V]) Key(parent any) (any, error) {
+	t, ok := parent.(T)
+	if !ok {
+		return nil, ErrIncompatibleTypes
+	}
+
+	return bl.key(t), nil
+}
+
+// Load calls the batch function with the keys, [ErrIncompatibleTypes] when they aren't [K].
+func (bl batchLoader[T, K, V]) Load(ctx context.Context, keys []any) (map[any]any, error) {
+	typedKeys := make([]K, len(keys))
+	for i, k := range keys {
+		var ok bool
+		if typedKeys[i], ok = k.(K); !ok {
+			return nil, ErrIncompatibleTypes
+		}
+	}
+
+	values, err := bl.batch(ctx, typedKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	loaded := make(map[any]any, len(values))
+	for k, v := range values {
+		loaded[k] = v
+	}
+
+	return loaded, nil
+}
+
+// Load creates a copy of [v], a struct or a slice of structs or pointers to them, with only the fields specified
+// in [n], and sets the relation fields of the [loaders] with the values they load.
+// Each loader only runs if its relation field is selected, once for all the keys found in [v], so the N+1 problem
+// is avoided, and the child selection of the field is applied to the loaded values.
+// The loaders run concurrently and every error is returned in a [ResolveError], with the path of the relation.
+// The value is deep copied, so setting the relation fields never changes [v].
+func Load[T any](ctx context.Context, n Node, v T, loaders Loaders, opts ...Option) (T, error) {
+	var zero T
+
+	if err := ctx.Err(); err != nil {
+		return zero, err
+	}
+
+	o := newOptions(opts)
+	o.deepCopy = true
+
+	c := copier{opts: o}
+
+	var selected T
+
+	src, dst := reflect.ValueOf(&v).Elem(), reflect.ValueOf(&selected).Elem()
+	if err := c.copyValue(n, src, dst); err != nil {
+		return zero, err
+	}
+
+	// the relations are loaded from the shallowest to the deepest, so the nested relations of loaded values are
+	// loaded too, e.g. `customer.company` after `customer`
+	roots := map[string][]loadRoot{"": {{node: n, src: src, dst: dst}}}
+
+	var errs []error
+
+	for _, level := range loaderLevels(loaders) {
+		collectors := make([]*loadCollector, len(level))
+
+		var wg sync.WaitGroup
+
+		for i, path := range level {
+			prefix, rest := loadedPrefix(roots, path)
+
+			lc := &loadCollector{opts: o}
+			for _, root := range roots[prefix] {
+				lc.collect(root.node, root.src, root.dst, rest, loaders[path])
+			}
+
+			collectors[i] = lc
+
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				lc.err = lc.run(ctx, loaders[path])
+			}()
+		}
+
+		wg.Wait()
+
+		for i, lc := range collectors {
+			if lc.err != nil {
+				errs = append(errs, NewFieldError(level[i], lc.err))
+
+				continue
+			}
+
+			roots[level[i]] = lc.loaded
+		}
+	}
+
+	if len(errs) > 0 {
+		return zero, NewResolveError(errs)
+	}
+
+	return selected, nil
+}
+
+// loaderLevels returns the paths of the loaders grouped by their depth, from the shallowest.
+func loaderLevels(loaders Loaders) [][]string {
+	var levels [][]string
+
+	for _, path := range slices.Sorted(maps.Keys(loaders)) {
+		depth := strings.Count(path, ".")
+		for len(levels) <= depth {
+			levels = append(levels, nil)
+		}
+
+		levels[depth] = append(levels[depth], path)
+	}
+
+	return levels
+}
+
+// loadedPrefix returns the longest path already loaded that path goes through, the root one at least, and the
+// rest of the path.
+func loadedPrefix(roots map[string][]loadRoot, path string) (string, []string) {
+	segments := strings.Split(path, ".")
+
+	for i := len(segments) - 1; i > 0; i-- {
+		prefix := strings.Join(segments[:i], ".")
+		if _, ok := roots[prefix]; ok {
+			return prefix, segments[i:]
+		}
+	}
+
+	return "", segments
+}
+
+// run loads the values of the keys of the targets and sets them.
+func (lc *loadCollector) run(ctx context.Context, loader Loader) error {
+	if lc.err != nil || len(lc.targets) == 0 {
+		// the relation isn't selected, or it isn't in the value
+		return lc.err
+	}
+
+	keys := make([]any, 0, len(lc.targets))
+	seen := make(map[any]struct{}, len(lc.targets))
+
+	for _, target := range lc.targets {
+		if _, ok := seen[target.key]; !ok {
+			seen[target.key] = struct{}{}
+			keys = append(keys, target.key)
+		}
+	}
+
+	loaded, err := loader.Load(ctx, keys)
+	if err != nil {
+		return err
+	}
+
+	p := projector{opts: lc.opts}
+
+	for _, target := range lc.targets {
+		value, ok := loaded[target.key]
+		if !ok || value == nil {
+			target.dst.SetZero()
+
+			continue
+		}
+
+		// values and pointers are converted into each other
+		v := reflect.ValueOf(value)
+		if err = p.projectValue(target.node, v, target.dst); err != nil {
+			return err
+		}
+
+		lc.loaded = append(lc.loaded, loadRoot{node: target.node, src: v, dst: target.dst})
+	}
+
+	return nil
+}
+
+// collect finds the relation fields at the end of path in src, the source value, and dst, its deep copy,
+// only going through the selected fields.
+//
+//nolint:exhaustive // only structs have fields
+func (lc *loadCollector) collect(node Node, src, dst reflect.Value, path []string, loader Loader) {
+	if lc.err != nil || isLeafType(src.Type()) {
+		return
+	}
+
+	switch src.Kind() {
+	case reflect.Ptr:
+		if src.IsNil() || dst.IsNil() {
+			return
+		}
+
+		lc.collect(node, src.Elem(), dst.Elem(), path, loader)
+	case reflect.Slice, reflect.Array:
+		for i := range min(src.Len(), dst.Len()) {
+			lc.collect(node, src.Index(i), dst.Index(i), path, loader)
+		}
+	case reflect.Struct:
+		t := src.Type()
+
+		fp, ok := lc.opts.field(lc.opts.planFor(t), path[0])
+		if !ok {
+			return
+		}
+
+		child, ok := lc.opts.selectedField(node, t, fp)
+		if !ok {
+			return
+		}
+
+		if len(path) > 1 {
+			sf, srcOK := fieldByIndex(src, fp.index)
+			df, dstOK := fieldByIndex(dst, fp.index)
+
+			if srcOK && dstOK {
+				lc.collect(child, sf, df, path[1:], loader)
+			}
+
+			return
+		}
+
+		key, err := loader.Key(src.Interface())
+		if err != nil {
+			lc.err = err
+
+			return
+		}
+
+		lc.targets = append(lc.targets, loadTarget{key: key, node: child, dst: settableFieldByIndex(dst, fp.index)})
+	}
+}
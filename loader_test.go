@@ -0,0 +1,168 @@
+package gofieldselect
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync/atomic"
+	"testing"
+)
+
+type (
+	purchase struct {
+		ID         int       `json:"id"`
+		CustomerID int       `json:"customerId"`
+		Customer   *customer `json:"customer"`
+	}
+
+	customer struct {
+		ID        int      `json:"id"`
+		Name      string   `json:"name"`
+		CompanyID int      `json:"companyId"`
+		Company   *company `json:"company"`
+	}
+
+	company struct {
+		Name string `json:"name"`
+		City string `json:"city"`
+	}
+)
+
+func newPurchases() []purchase {
+	return []purchase{{ID: 1, CustomerID: 10}, {ID: 2, CustomerID: 20}, {ID: 3, CustomerID: 10}}
+}
+
+func newPurchaseLoaders(customerCalls, companyCalls *atomic.Int32, keys *[]int) Loaders {
+	return Loaders{
+		"customer": NewLoader(func(p purchase) int { return p.CustomerID },
+			func(_ context.Context, ids []int) (map[int]*customer, error) {
+				customerCalls.Add(1)
+				*keys = ids
+
+				return map[int]*customer{
+					10: {ID: 10, Name: "John", CompanyID: 100},
+					20: {ID: 20, Name: "Jane", CompanyID: 100},
+				}, nil
+			}),
+		"customer.company": NewLoader(func(c customer) int { return c.CompanyID },
+			func(_ context.Context, ids []int) (map[int]company, error) {
+				companyCalls.Add(1)
+
+				return map[int]company{100: {Name: "ACME", City: "Madrid"}}, nil
+			}),
+	}
+}
+
+func TestLoadBatch(t *testing.T) {
+	t.Parallel()
+
+	var customerCalls, companyCalls atomic.Int32
+
+	var keys []int
+
+	src := newPurchases()
+
+	got, err := Load(t.Context(), parse(t, "id,customer(name,company(city))"), src,
+		newPurchaseLoaders(&customerCalls, &companyCalls, &keys))
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	if customerCalls.Load() != 1 || companyCalls.Load() != 1 {
+		t.Fatalf("expected a single batch per loader, got %d and %d", customerCalls.Load(), companyCalls.Load())
+	}
+
+	if !slices.Equal(keys, []int{10, 20}) {
+		t.Fatalf("expected the unique keys, got %v", keys)
+	}
+
+	if got[0].CustomerID != 0 || got[2].Customer.Name != "John" || got[1].Customer.Name != "Jane" {
+		t.Fatalf("expected the selected customers, got %+v", got)
+	}
+
+	if got[0].Customer.ID != 0 || got[0].Customer.Company.City != "Madrid" || got[0].Customer.Company.Name != "" {
+		t.Fatalf("expected the selection applied to the loaded values, got %+v", got[0].Customer)
+	}
+
+	if src[0].Customer != nil {
+		t.Fatalf("expected the source unchanged, got %+v", src[0])
+	}
+}
+
+func TestLoadNotSelected(t *testing.T) {
+	t.Parallel()
+
+	var customerCalls, companyCalls atomic.Int32
+
+	var keys []int
+
+	got, err := Load(t.Context(), parse(t, "id,customer(name)"), newPurchases(),
+		newPurchaseLoaders(&customerCalls, &companyCalls, &keys))
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	if customerCalls.Load() != 1 || companyCalls.Load() != 0 {
+		t.Fatalf("expected only the customer loader to run, got %d and %d", customerCalls.Load(), companyCalls.Load())
+	}
+
+	if got[0].Customer.Company != nil {
+		t.Fatalf("expected no company, got %+v", got[0].Customer.Company)
+	}
+}
+
+func TestLoadError(t *testing.T) {
+	t.Parallel()
+
+	loaders := Loaders{
+		"customer": NewLoader(func(p purchase) int { return p.CustomerID },
+			func(_ context.Context, _ []int) (map[int]customer, error) {
+				return nil, errors.New("boom")
+			}),
+	}
+
+	_, err := Load(t.Context(), parse(t, "customer"), newPurchases(), loaders)
+
+	var fe FieldError
+	if !errors.As(err, &fe) || fe.Path() != "customer" {
+		t.Fatalf("expected the error of the customer loader, got %v", err)
+	}
+}
+
+// customerLoader is a Loader implemented without NewLoader.
+type customerLoader map[int]customer
+
+func (cl customerLoader) Key(parent any) (any, error) {
+	p, ok := parent.(purchase)
+	if !ok {
+		return nil, ErrIncompatibleTypes
+	}
+
+	return p.CustomerID, nil
+}
+
+func (cl customerLoader) Load(_ context.Context, keys []any) (map[any]any, error) {
+	loaded := make(map[any]any, len(keys))
+	for _, k := range keys {
+		if c, ok := cl[k.(int)]; ok {
+			loaded[k] = c
+		}
+	}
+
+	return loaded, nil
+}
+
+func TestLoadCustomLoader(t *testing.T) {
+	t.Parallel()
+
+	loaders := Loaders{"customer": customerLoader{10: {ID: 10, Name: "John"}}}
+
+	got, err := Load(t.Context(), parse(t, "id,customer(name)"), newPurchases(), loaders)
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	if got[0].Customer.Name != "John" || got[0].Customer.ID != 0 || got[1].Customer != nil {
+		t.Fatalf("expected the customers loaded by the custom loader, got %+v %+v", got[0].Customer, got[1].Customer)
+	}
+}
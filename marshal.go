@@ -0,0 +1,437 @@
+package gofieldselect
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+var _ json.Marshaler = Selected[any]{}
+
+type (
+	// Selected wraps a value with a selection, so only the selected fields are written when it is encoded to JSON.
+	// A nil Node selects every field.
+	Selected[T any] struct {
+		Value   T
+		Node    Node
+		Options []Option
+	}
+
+	// Encoder writes the JSON encoding of values with only the fields of a selection, see [NewEncoder].
+	Encoder struct {
+		w      io.Writer
+		n      Node
+		opts   []Option
+		prefix string
+		indent string
+	}
+
+	// jsonEncoder writes JSON applying a selection, following the encoding/json tag semantics.
+	jsonEncoder struct {
+		opts options
+		buf  bytes.Buffer
+		// visiting holds the pointers being encoded with a wildcard selection, to detect cycles.
+		visiting map[visitKey]struct{}
+	}
+)
+
+// Marshal returns the JSON encoding of [v] with only the fields specified in [n], writing them straight
+// from [v] without intermediate structs or maps.
+// It follows the encoding/json struct tags semantics, `-`, `omitempty`, `omitzero` and `string`, and a nil [n]
+// selects every field, except the `explicit` and `never` ones of the `fieldselect` tag.
+// Use [WithSelectionOrder] to write the keys in the order of the selection, and [WithTagKey], [WithNaming] and
+// [WithCaseInsensitive] to change how the selected names match the fields.
+func Marshal(n Node, v any, opts ...Option) ([]byte, error) {
+	if n == nil {
+		n = AllIdentifiers{}
+	}
+
+	e := jsonEncoder{opts: newOptions(opts)}
+	if err := e.encode(n, reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+
+	return e.buf.Bytes(), nil
+}
+
+// NewEncoder returns a new encoder that writes to [w] the values with only the fields specified in [n].
+func NewEncoder(w io.Writer, n Node, opts ...Option) *Encoder {
+	return &Encoder{w: w, n: n, opts: opts}
+}
+
+// SetIndent makes the encoder indent each encoded value, like [json.Encoder.SetIndent].
+func (enc *Encoder) SetIndent(prefix, indent string) {
+	enc.prefix = prefix
+	enc.indent = indent
+}
+
+// Encode writes the selected JSON encoding of [v] followed by a newline character.
+func (enc *Encoder) Encode(v any) error {
+	b, err := Marshal(enc.n, v, enc.opts...)
+	if err != nil {
+		return err
+	}
+
+	if enc.prefix != "" || enc.indent != "" {
+		var indented bytes.Buffer
+		if err = json.Indent(&indented, b, enc.prefix, enc.indent); err != nil {
+			return err
+		}
+
+		b = indented.Bytes()
+	}
+
+	b = append(b, '\n')
+	_, err = enc.w.Write(b)
+
+	return err
+}
+
+// MarshalJSON returns the JSON encoding of the value with only the selected fields.
+func (s Selected[T]) MarshalJSON() ([]byte, error) {
+	return Marshal(s.Node, s.Value, s.Options...)
+}
+
+// encode writes the JSON encoding of v with only the fields in node.
+//
+//nolint:exhaustive // the rest of kinds are encoded by encoding/json
+func (e *jsonEncoder) encode(node Node, v reflect.Value) error {
+	if !v.IsValid() {
+		e.buf.WriteString("null")
+
+		return nil
+	}
+
+	if isLeafType(v.Type()) {
+		if !isAllIdentifiers(node) {
+			return ErrChildSelectionOnLeaf
+		}
+
+		return e.encodeLeaf(v)
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return e.encodeStruct(node, v)
+	case reflect.Ptr:
+		return e.encodePtr(node, v)
+	case reflect.Interface:
+		if v.IsNil() {
+			e.buf.WriteString("null")
+
+			return nil
+		}
+
+		return e.encode(node, v.Elem())
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			e.buf.WriteString("null")
+
+			return nil
+		}
+
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			// bytes are encoded as a base64 string
+			return e.encodeLeaf(v)
+		}
+
+		return e.encodeElements(node, v)
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return e.encodeLeaf(v)
+		}
+
+		return e.encodeMap(node, v)
+	default:
+		return e.encodeLeaf(v)
+	}
+}
+
+// encodeLeaf writes v using encoding/json, calling the pointer receiver marshalers when v is addressable.
+func (e *jsonEncoder) encodeLeaf(v reflect.Value) error {
+	value := v.Interface()
+	if v.CanAddr() {
+		value = v.Addr().Interface()
+	}
+
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	e.buf.Write(b)
+
+	return nil
+}
+
+func (e *jsonEncoder) encodePtr(node Node, v reflect.Value) error {
+	if v.IsNil() {
+		e.buf.WriteString("null")
+
+		return nil
+	}
+
+	if !isAllIdentifiers(node) {
+		return e.encode(node, v.Elem())
+	}
+
+	key := visitKey{ptr: v.Pointer(), typ: v.Type()}
+	if _, ok := e.visiting[key]; ok {
+		return ErrCyclicValue
+	}
+
+	if e.visiting == nil {
+		e.visiting = make(map[visitKey]struct{})
+	}
+
+	e.visiting[key] = struct{}{}
+	defer delete(e.visiting, key)
+
+	return e.encode(node, v.Elem())
+}
+
+func (e *jsonEncoder) encodeStruct(node Node, v reflect.Value) error {
+	t := v.Type()
+	tp := e.opts.planFor(t)
+
+	e.buf.WriteByte('{')
+
+	first := true
+
+	fields := tp.fields
+	if identifiers, ok := node.(Identifiers); ok && e.opts.selectionOrder {
+		fields = selectionOrderedFields(e.opts, tp, identifiers)
+	}
+
+	for _, fp := range fields {
+		if fp.ignored {
+			continue
+		}
+
+		child, selected := e.opts.selectedField(node, t, fp)
+		if !selected {
+			continue
+		}
+
+		fv, exists := fieldByIndex(v, fp.index)
+		if !exists {
+			continue
+		}
+
+		if err := e.encodeStructField(fp, child, fv, &first); err != nil {
+			return err
+		}
+	}
+
+	e.buf.WriteByte('}')
+
+	return nil
+}
+
+// selectionOrderedFields returns the fields named in identifiers in selection order, skipping the unknown and
+// duplicated ones, followed by the rest of the fields in declaration order.
+func selectionOrderedFields(o options, tp *typePlan, identifiers Identifiers) []fieldPlan {
+	fields := make([]fieldPlan, 0, len(tp.fields))
+	named := make(map[int]struct{}, len(identifiers))
+
+	for _, ident := range identifiers {
+		fp, found := o.field(tp, ident.Value)
+		if !found {
+			continue
+		}
+
+		i := tp.byName[fp.name]
+		if _, done := named[i]; !done {
+			named[i] = struct{}{}
+			fields = append(fields, fp)
+		}
+	}
+
+	for i, fp := range tp.fields {
+		if _, done := named[i]; !done {
+			// only the `always` ones are selected without being named
+			fields = append(fields, fp)
+		}
+	}
+
+	return fields
+}
+
+// encodeStructField writes the key and value of a selected struct field, unless it is omitted by its JSON tag.
+func (e *jsonEncoder) encodeStructField(fp fieldPlan, node Node, fv reflect.Value, first *bool) error {
+	if (fp.omitEmpty && isEmptyValue(fv)) || (fp.omitZero && isZeroValue(fv)) {
+		return nil
+	}
+
+	if !*first {
+		e.buf.WriteByte(',')
+	}
+
+	*first = false
+
+	e.encodeKey(fp.name)
+
+	if err := e.encodeField(fp, node, fv); err != nil {
+		return wrapFieldError(fp.name, err)
+	}
+
+	return nil
+}
+
+// encodeField writes the value of a struct field, quoting it if the JSON tag has the `string` option.
+func (e *jsonEncoder) encodeField(fp fieldPlan, node Node, v reflect.Value) error {
+	if !fp.quoted {
+		return e.encode(node, v)
+	}
+
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			e.buf.WriteString("null")
+
+			return nil
+		}
+
+		v = v.Elem()
+	}
+
+	b, err := json.Marshal(v.Interface())
+	if err != nil {
+		return err
+	}
+
+	quoted, err := json.Marshal(string(b))
+	if err != nil {
+		return err
+	}
+
+	e.buf.Write(quoted)
+
+	return nil
+}
+
+func (e *jsonEncoder) encodeElements(node Node, v reflect.Value) error {
+	e.buf.WriteByte('[')
+
+	for i := range v.Len() {
+		if i > 0 {
+			e.buf.WriteByte(',')
+		}
+
+		if err := e.encode(node, v.Index(i)); err != nil {
+			return err
+		}
+	}
+
+	e.buf.WriteByte(']')
+
+	return nil
+}
+
+// encodeMap writes the selected entries of a map with string keys, sorted by key like encoding/json does
+// or in selection order.
+func (e *jsonEncoder) encodeMap(node Node, v reflect.Value) error {
+	if v.IsNil() {
+		e.buf.WriteString("null")
+
+		return nil
+	}
+
+	keys := v.MapKeys()
+	if identifiers, ok := node.(Identifiers); ok && e.opts.selectionOrder {
+		keys = selectionOrderedKeys(identifiers, v)
+	} else {
+		slices.SortFunc(keys, func(a, b reflect.Value) int {
+			return strings.Compare(a.String(), b.String())
+		})
+	}
+
+	e.buf.WriteByte('{')
+
+	first := true
+
+	for _, key := range keys {
+		ident, ok := e.opts.selectField(node, key.String())
+		if !ok {
+			continue
+		}
+
+		if !first {
+			e.buf.WriteByte(',')
+		}
+
+		first = false
+
+		e.encodeKey(key.String())
+
+		if err := e.encode(childNode(ident), v.MapIndex(key)); err != nil {
+			return wrapFieldError(key.String(), err)
+		}
+	}
+
+	e.buf.WriteByte('}')
+
+	return nil
+}
+
+// selectionOrderedKeys returns the keys of the map with string keys in the order of the identifiers,
+// skipping the missing and duplicated ones.
+func selectionOrderedKeys(identifiers Identifiers, v reflect.Value) []reflect.Value {
+	keys := make([]reflect.Value, 0, len(identifiers))
+	seen := make(map[string]struct{}, len(identifiers))
+
+	for _, ident := range identifiers {
+		if _, ok := seen[ident.Value]; ok {
+			continue
+		}
+
+		seen[ident.Value] = struct{}{}
+
+		key := reflect.ValueOf(ident.Value).Convert(v.Type().Key())
+		if v.MapIndex(key).IsValid() {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
+func (e *jsonEncoder) encodeKey(key string) {
+	// a string can always be encoded
+	b, _ := json.Marshal(key)
+	e.buf.Write(b)
+	e.buf.WriteByte(':')
+}
+
+// isEmptyValue reports whether v is empty for the `omitempty` option, like encoding/json does.
+//
+//nolint:exhaustive // the rest of kinds are never empty
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Interface, reflect.Ptr:
+		return v.IsZero()
+	default:
+		return false
+	}
+}
+
+// isZeroValue reports whether v is zero for the `omitzero` option, using its IsZero method if it has one.
+func isZeroValue(v reflect.Value) bool {
+	if z, ok := v.Interface().(interface{ IsZero() bool }); ok {
+		if v.Kind() == reflect.Ptr && v.IsNil() {
+			return true
+		}
+
+		return z.IsZero()
+	}
+
+	return v.IsZero()
+}
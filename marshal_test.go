@@ -0,0 +1,213 @@
+package gofieldselect
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+type Article struct {
+	ID        int               `json:"id,string"`
+	Title     string            `json:"title"`
+	Body      string            `json:"body,omitempty"`
+	Views     int               `json:"views"`
+	Secret    string            `json:"-"`
+	Raw       json.RawMessage   `json:"raw,omitempty"`
+	CreatedAt time.Time         `json:"createdAt"`
+	Deleted   time.Time         `json:"deleted,omitzero"`
+	Author    *UserPtr          `json:"author"`
+	Tags      []string          `json:"tags"`
+	Meta      map[string]any    `json:"meta"`
+	Bytes     []byte            `json:"bytes"`
+	Counts    map[int]int       `json:"counts"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	Base
+	articleStats
+	*articleSource
+}
+
+// articleStats and articleSource are unexported, but encoding/json still promotes their fields.
+type (
+	articleStats struct {
+		Likes  int `json:"likes"`
+		Shares int `json:"shares,omitempty"`
+	}
+
+	articleSource struct {
+		Source string `json:"source"`
+	}
+)
+
+func newArticle() Article {
+	return Article{
+		ID:            7,
+		Title:         "Hello",
+		Secret:        "secret",
+		Raw:           json.RawMessage(`{"a":1}`),
+		CreatedAt:     time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		Author:        &UserPtr{Name: "John", Address: &Address{Street: "Main", Number: 1}},
+		Tags:          []string{"go"},
+		Meta:          map[string]any{"b": 2, "a": "<1>"},
+		Bytes:         []byte("bytes"),
+		Counts:        map[int]int{1: 2},
+		Base:          Base{ID: 3, Name: "base"},
+		articleStats:  articleStats{Likes: 5},
+		articleSource: &articleSource{Source: "feed"},
+	}
+}
+
+func TestMarshalAllFieldsMatchesEncodingJSON(t *testing.T) {
+	t.Parallel()
+
+	a := newArticle()
+
+	expected, err := json.Marshal(a)
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	got, err := Marshal(nil, a)
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	if !bytes.Equal(got, expected) {
+		t.Fatalf("expected %s; got %s", expected, got)
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		selection string
+		expected  string
+	}{
+		"root fields": {
+			selection: "id,views,body",
+			expected:  `{"id":"7","views":0}`,
+		},
+		"nested fields": {
+			selection: "author(address(street)),meta(a)",
+			expected:  `{"author":{"address":{"street":"Main"}},"meta":{"a":"\u003c1\u003e"}}`,
+		},
+		"ignored field": {
+			selection: "secret,Secret",
+			expected:  `{}`,
+		},
+		"promoted from unexported embedded types": {
+			selection: "source,shares,likes",
+			expected:  `{"likes":5,"source":"feed"}`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := Marshal(parse(t, tc.selection), newArticle())
+			if err != nil {
+				t.Fatalf("error: %v", err)
+			}
+
+			if string(got) != tc.expected {
+				t.Fatalf("expected %s; got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestMarshalErrors(t *testing.T) {
+	t.Parallel()
+
+	if _, err := Marshal(parse(t, "createdAt(foo)"), newArticle()); !errors.Is(err, ErrChildSelectionOnLeaf) {
+		t.Fatalf("expected ErrChildSelectionOnLeaf; got %v", err)
+	}
+
+	type Link struct {
+		Next *Link `json:"next"`
+	}
+
+	cyclic := &Link{}
+	cyclic.Next = cyclic
+
+	if _, err := Marshal(parse(t, ""), cyclic); !errors.Is(err, ErrCyclicValue) {
+		t.Fatalf("expected ErrCyclicValue; got %v", err)
+	}
+}
+
+func TestSelected(t *testing.T) {
+	t.Parallel()
+
+	got, err := json.Marshal(map[string]any{
+		"data": Selected[[]User]{
+			Value: []User{{Name: "John", Age: 20}, {Name: "Jane"}},
+			Node:  parse(t, "name,age"),
+		},
+	})
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	expected := `{"data":[{"name":"John","age":20},{"name":"Jane","age":0}]}`
+	if string(got) != expected {
+		t.Fatalf("expected %s; got %s", expected, got)
+	}
+}
+
+func TestEncoder(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	enc := NewEncoder(&buf, parse(t, "name"))
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(User{Name: "John", Age: 20}); err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	expected := "{\n  \"name\": \"John\"\n}\n"
+	if buf.String() != expected {
+		t.Fatalf("expected %q; got %q", expected, buf.String())
+	}
+}
+
+func TestMarshalWithSelectionOrder(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		selection string
+		expected  string
+	}{
+		"root fields": {
+			selection: "views,title,id,views",
+			expected:  `{"views":0,"title":"Hello","id":"7"}`,
+		},
+		"nested fields": {
+			selection: "meta(b,a),author(address(number,street),name)",
+			expected:  `{"meta":{"b":2,"a":"\u003c1\u003e"},"author":{"address":{"number":1,"street":"Main"},"name":"John"}}`,
+		},
+		"wildcard keeps declaration order": {
+			selection: "author,title",
+			expected:  `{"author":{"name":"John","surname":"","age":0,"address":{"street":"Main","number":1}},"title":"Hello"}`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := Marshal(parse(t, tc.selection), newArticle(), WithSelectionOrder())
+			if err != nil {
+				t.Fatalf("error: %v", err)
+			}
+
+			if string(got) != tc.expected {
+				t.Fatalf("expected %s; got %s", tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package gofieldselect
+
+import (
+	"maps"
+	"slices"
+)
+
+// mongoIDField is the field MongoDB includes unless it's explicitly excluded.
+const mongoIDField = "_id"
+
+// ToMongoProjection returns the MongoDB projection document of the selection [n], e.g. `{"address.street": 1}`,
+// excluding `_id` when it isn't selected.
+// When every field is selected, the [excludes] paths are excluded instead, e.g. `{"password": 0}`, since MongoDB
+// doesn't mix inclusions and exclusions.
+// A selection without fields, e.g. `address()`, returns [ErrEmptySelection], since an empty projection selects
+// every field.
+func ToMongoProjection(n Node, excludes ...string) (map[string]any, error) {
+	paths := nodePaths(n)
+	if paths == nil {
+		projection := make(map[string]any, len(excludes))
+		for _, path := range excludes {
+			projection[path] = 0
+		}
+
+		return projection, nil
+	}
+
+	if len(paths) == 0 {
+		return nil, ErrEmptySelection
+	}
+
+	projection := make(map[string]any, len(paths)+1)
+	for _, path := range paths {
+		projection[path] = 1
+	}
+
+	if _, ok := n.SelectField(mongoIDField); !ok {
+		projection[mongoIDField] = 0
+	}
+
+	return projection, nil
+}
+
+// FromMongoProjection returns the selection of a MongoDB projection document, the inverse of [ToMongoProjection].
+// The values can be numbers or booleans, and an exclusion projection returns every field and the excluded paths.
+// Mixing inclusions and exclusions, other than the `_id` one, returns [ErrInvalidProjection].
+func FromMongoProjection(projection map[string]any) (Node, []string, error) {
+	var includes, excludes []string
+
+	for _, path := range slices.Sorted(maps.Keys(projection)) {
+		include, ok := mongoInclusion(projection[path])
+		if !ok {
+			return nil, nil, NewFieldError(path, ErrInvalidProjection)
+		}
+
+		if include {
+			includes = append(includes, path)
+		} else {
+			excludes = append(excludes, path)
+		}
+	}
+
+	if len(includes) > 0 {
+		// the `_id` exclusion is the only one allowed with inclusions
+		excludes = slices.DeleteFunc(excludes, func(path string) bool { return path == mongoIDField })
+		if len(excludes) > 0 {
+			return nil, nil, ErrInvalidProjection
+		}
+
+		excludes = nil
+	}
+
+	n, err := nodeFromPaths(includes)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return n, excludes, nil
+}
+
+// mongoInclusion returns whether a projection value includes the field, false if it isn't a number or a boolean.
+func mongoInclusion(v any) (bool, bool) {
+	switch v := v.(type) {
+	case bool:
+		return v, true
+	case int:
+		return v != 0, true
+	case int8:
+		return v != 0, true
+	case int16:
+		return v != 0, true
+	case int32:
+		return v != 0, true
+	case int64:
+		return v != 0, true
+	case uint:
+		return v != 0, true
+	case uint8:
+		return v != 0, true
+	case uint16:
+		return v != 0, true
+	case uint32:
+		return v != 0, true
+	case uint64:
+		return v != 0, true
+	case float32:
+		return v != 0, true
+	case float64:
+		return v != 0, true
+	default:
+		return false, false
+	}
+}
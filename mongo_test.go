@@ -0,0 +1,101 @@
+package gofieldselect
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestToMongoProjection(t *testing.T) {
+	t.Parallel()
+
+	got := mongoProjection(t, parse(t, "name,address(street,number),tags()"))
+	expected := map[string]any{"name": 1, "address.street": 1, "address.number": 1, "_id": 0}
+
+	if !maps.Equal(got, expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+
+	got = mongoProjection(t, parse(t, "_id,name"))
+	if !maps.Equal(got, map[string]any{"_id": 1, "name": 1}) {
+		t.Fatalf("expected the _id included, got %v", got)
+	}
+
+	got = mongoProjection(t, parse(t, ""), "password", "address.secret")
+	if !maps.Equal(got, map[string]any{"password": 0, "address.secret": 0}) {
+		t.Fatalf("expected the exclusions, got %v", got)
+	}
+
+	for _, n := range []Node{parse(t, "address()"), Identifiers{}} {
+		if _, err := ToMongoProjection(n); !errors.Is(err, ErrEmptySelection) {
+			t.Fatalf("%s: expected ErrEmptySelection, got %v", canonical(n), err)
+		}
+	}
+}
+
+func mongoProjection(t *testing.T, n Node, excludes ...string) map[string]any {
+	t.Helper()
+
+	projection, err := ToMongoProjection(n, excludes...)
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	return projection
+}
+
+func TestFromMongoProjection(t *testing.T) {
+	t.Parallel()
+
+	for _, sel := range []string{"name,address(street,number)", "address,name", "_id"} {
+		n, excludes, err := FromMongoProjection(mongoProjection(t, parse(t, sel)))
+		if err != nil {
+			t.Fatalf("%q: error: %v", sel, err)
+		}
+
+		if canonical(n) != canonical(parse(t, sel)) || excludes != nil {
+			t.Fatalf("%q: expected the same selection, got %s and %v", sel, canonical(n), excludes)
+		}
+	}
+
+	n, excludes, err := FromMongoProjection(map[string]any{"password": false, "_id": 0.0})
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	if !isAllIdentifiers(n) || !slices.Equal(excludes, []string{"_id", "password"}) {
+		t.Fatalf("expected every field but the exclusions, got %s and %v", canonical(n), excludes)
+	}
+
+	n, _, err = FromMongoProjection(map[string]any{"address": 1, "address.street": 1})
+	if err != nil || canonical(n) != "(address)" {
+		t.Fatalf("expected the parent path to hide the child, got %v, %v", n, err)
+	}
+
+	n, _, err = FromMongoProjection(map[string]any{
+		"a": int8(1), "b": int16(1), "c": uint(1), "d": uint8(1), "e": uint16(1), "f": uint32(1), "g": uint64(1),
+		"h": float32(1), "_id": float32(0),
+	})
+	if err != nil || canonical(n) != "(a,b,c,d,e,f,g,h)" {
+		t.Fatalf("expected every numeric kind accepted, got %v, %v", n, err)
+	}
+}
+
+func TestFromMongoProjectionInvalid(t *testing.T) {
+	t.Parallel()
+
+	for _, projection := range []map[string]any{
+		{"name": 1, "password": 0},
+		{"name": "yes"},
+		{"address..street": 1},
+	} {
+		if _, _, err := FromMongoProjection(projection); err == nil {
+			t.Fatalf("%v: expected an error", projection)
+		}
+	}
+
+	if _, _, err := FromMongoProjection(map[string]any{"name": 1, "password": 0}); !errors.Is(err, ErrInvalidProjection) {
+		t.Fatalf("expected ErrInvalidProjection, got %v", err)
+	}
+}
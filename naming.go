@@ -0,0 +1,133 @@
+package gofieldselect
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// Naming is the strategy used to get the selection name of the fields without a name in their tag.
+type Naming int
+
+const (
+	// NamingFieldName uses the Go field name as it is, e.g. `CreatedAt`, like encoding/json does.
+	NamingFieldName Naming = iota
+	// NamingCamelCase uses the Go field name in camelCase, e.g. `createdAt`.
+	NamingCamelCase
+	// NamingSnakeCase uses the Go field name in snake_case, e.g. `created_at`.
+	NamingSnakeCase
+)
+
+// defaultTagKey is the struct tag used to get the selection names when no other one is configured.
+const defaultTagKey = "json"
+
+// name returns the selection name of a field named fieldName with the strategy.
+func (n Naming) name(fieldName string) string {
+	switch n {
+	case NamingCamelCase:
+		words := splitWords(fieldName)
+		for i, w := range words {
+			w = strings.ToLower(w)
+			if i > 0 {
+				w = strings.ToUpper(w[:1]) + w[1:]
+			}
+
+			words[i] = w
+		}
+
+		return strings.Join(words, "")
+	case NamingSnakeCase:
+		words := splitWords(fieldName)
+		for i, w := range words {
+			words[i] = strings.ToLower(w)
+		}
+
+		return strings.Join(words, "_")
+	default:
+		return fieldName
+	}
+}
+
+// splitWords splits an identifier into its words, keeping the acronyms together, e.g. `UserID` into `User` and
+// `ID` and `HTTPServer` into `HTTP` and `Server`.
+func splitWords(s string) []string {
+	var words []string
+
+	runes := []rune(s)
+	start := 0
+
+	for i := range runes {
+		switch {
+		case runes[i] == '_':
+			if i > start {
+				words = append(words, string(runes[start:i]))
+			}
+
+			start = i + 1
+		case i > start && unicode.IsUpper(runes[i]):
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+
+			if !unicode.IsUpper(prev) || nextLower {
+				words = append(words, string(runes[start:i]))
+				start = i
+			}
+		}
+	}
+
+	if start < len(runes) {
+		words = append(words, string(runes[start:]))
+	}
+
+	return words
+}
+
+// planFor returns the plan of the struct type t with the configured tag key and naming strategy.
+func (o options) planFor(t reflect.Type) *typePlan {
+	return planForKey(planKey{typ: t, tagKey: o.nameTagKey(), naming: o.naming})
+}
+
+// nameTagKey returns the struct tag used to get the selection names.
+func (o options) nameTagKey() string {
+	if o.tagKey == "" {
+		return defaultTagKey
+	}
+
+	return o.tagKey
+}
+
+// selectField returns the identifier of node that selects the field name, ignoring the case if configured.
+func (o options) selectField(node Node, name string) (Identifier, bool) {
+	ident, ok := node.SelectField(name)
+	if ok || !o.caseInsensitive {
+		return ident, ok
+	}
+
+	identifiers, ok := node.(Identifiers)
+	if !ok {
+		return Identifier{}, false
+	}
+
+	for _, ident = range identifiers {
+		if strings.EqualFold(ident.Value, name) {
+			return ident, true
+		}
+	}
+
+	return Identifier{}, false
+}
+
+// field returns the plan of the selectable field whose selection key is name, ignoring the case if configured.
+func (o options) field(tp *typePlan, name string) (fieldPlan, bool) {
+	fp, ok := tp.field(name)
+	if ok || !o.caseInsensitive {
+		return fp, ok
+	}
+
+	i, ok := tp.byFoldedName[strings.ToLower(name)]
+	if !ok {
+		return fieldPlan{}, false
+	}
+
+	return tp.fields[i], true
+}
@@ -0,0 +1,201 @@
+package gofieldselect
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+type profile struct {
+	UserID    int
+	FirstName string
+	HTTPPort  int
+	Nickname  string `yaml:"nick" json:"nickname"`
+	Hidden    string `yaml:"-"`
+}
+
+func TestNamingStrategies(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		naming   Naming
+		expected string
+	}{
+		"UserID":    {naming: NamingCamelCase, expected: "userId"},
+		"HTTPPort":  {naming: NamingCamelCase, expected: "httpPort"},
+		"ID":        {naming: NamingCamelCase, expected: "id"},
+		"CreatedAt": {naming: NamingSnakeCase, expected: "created_at"},
+		"URLPath2":  {naming: NamingSnakeCase, expected: "url_path2"},
+		"Name":      {naming: NamingFieldName, expected: "Name"},
+	}
+
+	for name, test := range tests {
+		if got := test.naming.name(name); got != test.expected {
+			t.Fatalf("%s: expected %q, got %q", name, test.expected, got)
+		}
+	}
+}
+
+func TestGetWithReflectionTagKey(t *testing.T) {
+	t.Parallel()
+
+	src := profile{UserID: 1, Nickname: "jd", Hidden: "hidden"}
+
+	got, err := GetWithReflection(parse(t, "nick"), src, WithTagKey("yaml"))
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	if got.Nickname != "jd" || got.UserID != 0 {
+		t.Fatalf("expected only the nickname, got %+v", got)
+	}
+
+	if err = Validate[profile](parse(t, "Hidden"), WithTagKey("yaml")); !errors.Is(err, ErrUnknownField) {
+		t.Fatalf("expected ErrUnknownField, got %v", err)
+	}
+}
+
+func TestGetWithReflectionNaming(t *testing.T) {
+	t.Parallel()
+
+	src := profile{UserID: 1, FirstName: "John", HTTPPort: 80}
+
+	got, err := GetWithReflection(parse(t, "user_id,http_port"), src, WithNaming(NamingSnakeCase))
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	if got.UserID != 1 || got.HTTPPort != 80 || got.FirstName != "" {
+		t.Fatalf("expected userID and httpPort, got %+v", got)
+	}
+
+	if err = Validate[profile](parse(t, "firstName,nickname"), WithNaming(NamingCamelCase)); err != nil {
+		t.Fatalf("expected a valid selection, got %v", err)
+	}
+}
+
+func TestGetWithReflectionCaseInsensitive(t *testing.T) {
+	t.Parallel()
+
+	src := User{Name: "John", Surname: "Doe", Address: Address{Street: "Main", Number: 1}}
+
+	got, err := GetWithReflection(parse(t, "NAME,address(Street)"), src, WithCaseInsensitive())
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	if got.Name != "John" || got.Surname != "" || got.Address.Street != "Main" || got.Address.Number != 0 {
+		t.Fatalf("expected name and address street, got %+v", got)
+	}
+
+	if err = Validate[User](parse(t, "NAME,address(Street)"), WithCaseInsensitive()); err != nil {
+		t.Fatalf("expected a valid selection, got %v", err)
+	}
+
+	if err = Validate[User](parse(t, "NAME")); !errors.Is(err, ErrUnknownField) {
+		t.Fatalf("expected ErrUnknownField without the option, got %v", err)
+	}
+}
+
+func TestGetWithOptions(t *testing.T) {
+	t.Parallel()
+
+	n := parse(t, "first_name,Age")
+
+	if got := Get(n, "FirstName", "John", WithNaming(NamingSnakeCase)); got != "John" {
+		t.Fatalf("expected John, got %q", got)
+	}
+
+	if got := Get(n, "age", 20, WithCaseInsensitive()); got != 20 {
+		t.Fatalf("expected 20, got %d", got)
+	}
+
+	if got := Get(n, "age", 20); got != 0 {
+		t.Fatalf("expected 0, got %d", got)
+	}
+}
+
+func TestNamingOptionsAcrossAPIs(t *testing.T) {
+	t.Parallel()
+
+	type dto struct {
+		UserID    int
+		FirstName string
+		Nickname  string
+	}
+
+	src := profile{UserID: 1, FirstName: "John", Nickname: "jo"}
+	n := parse(t, "user_id,nick")
+	opts := []Option{WithTagKey("yaml"), WithNaming(NamingSnakeCase)}
+
+	b, err := Marshal(n, src, opts...)
+	if err != nil || string(b) != `{"user_id":1,"nick":"jo"}` {
+		t.Fatalf("unexpected JSON %s, %v", b, err)
+	}
+
+	m, err := ToMap(n, src, opts...)
+	if err != nil || len(m) != 2 || m["user_id"] != 1 || m["nick"] != "jo" {
+		t.Fatalf("unexpected map %v, %v", m, err)
+	}
+
+	project, err := Compile[profile](n, opts...)
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	if got := project(src); got != (profile{UserID: 1, Nickname: "jo"}) {
+		t.Fatalf("unexpected compiled projection %+v", got)
+	}
+
+	if _, err = Compile[profile](n); !errors.Is(err, ErrUnknownField) {
+		t.Fatalf("expected the json names without options, got %v", err)
+	}
+
+	applied := src
+	if err = Apply(n, &applied, opts...); err != nil || applied != (profile{UserID: 1, Nickname: "jo"}) {
+		t.Fatalf("unexpected applied value %+v, %v", applied, err)
+	}
+
+	projected, err := Project[profile, dto](parse(t, "userId,firstName"), src, WithNaming(NamingCamelCase))
+	if err != nil || projected != (dto{UserID: 1, FirstName: "John"}) {
+		t.Fatalf("unexpected projection %+v, %v", projected, err)
+	}
+
+	if pt := ProjectType[profile](n, opts...); pt == nil || pt.NumField() != 2 {
+		t.Fatalf("expected a type with two fields, got %v", pt)
+	}
+
+	var buf bytes.Buffer
+	if err = WriteCSV(&buf, n, []profile{src}, opts...); err != nil || buf.String() != "user_id,nick\n1,jo\n" {
+		t.Fatalf("unexpected CSV %q, %v", buf.String(), err)
+	}
+
+	if b, err = Marshal(parse(t, "FIRSTNAME"), src, WithCaseInsensitive()); err != nil || string(b) != `{"FirstName":"John"}` {
+		t.Fatalf("unexpected case insensitive JSON %s, %v", b, err)
+	}
+
+	if _, err = MarshalXML(n, src, opts...); !errors.Is(err, ErrUnsupportedOption) {
+		t.Fatalf("expected ErrUnsupportedOption for XML, got %v", err)
+	}
+}
+
+func TestFieldselectTagKey(t *testing.T) {
+	t.Parallel()
+
+	type account struct {
+		ID       int    `fieldselect:"name=id;always"`
+		FullName string `fieldselect:"name=fullName"`
+		Password string `fieldselect:"never"`
+	}
+
+	src := account{ID: 1, FullName: "John Doe", Password: "secret"}
+
+	got, err := GetWithReflection(parse(t, "fullName"), src, WithTagKey("fieldselect"))
+	if err != nil || got != (account{ID: 1, FullName: "John Doe"}) {
+		t.Fatalf("unexpected selection %+v, %v", got, err)
+	}
+
+	if err = Validate[account](parse(t, "always"), WithTagKey("fieldselect")); !errors.Is(err, ErrUnknownField) {
+		t.Fatalf("expected the tag options not to be names, got %v", err)
+	}
+}
@@ -1,5 +1,10 @@
 package gofieldselect
 
+import (
+	"slices"
+	"strings"
+)
+
 var (
 	_ Node = new(Identifiers)
 	_ Node = new(AllIdentifiers)
@@ -47,3 +52,74 @@ func (a AllIdentifiers) SelectField(fieldName string) (Identifier, bool) {
 }
 
 func (a AllIdentifiers) node() {}
+
+// canonical returns the selection as a string that is the same for equivalent selections,
+// sorting and removing duplicated identifiers.
+func canonical(n Node) string {
+	identifiers, ok := n.(Identifiers)
+	if !ok {
+		return "*"
+	}
+
+	children := make(map[string]string, len(identifiers))
+	for _, ident := range identifiers {
+		if _, ok = children[ident.Value]; !ok {
+			children[ident.Value] = canonical(childNode(ident))
+		}
+	}
+
+	names := make([]string, 0, len(children))
+	for name := range children {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	var sb strings.Builder
+
+	sb.WriteByte('(')
+
+	for i, name := range names {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+
+		sb.WriteString(name)
+
+		if child := children[name]; child != "*" {
+			sb.WriteString(child)
+		}
+	}
+
+	sb.WriteByte(')')
+
+	return sb.String()
+}
+
+// mergeNodes returns the union of both selections, merging the children of the identifiers in both.
+func mergeNodes(a, b Node) Node {
+	ai, ok := a.(Identifiers)
+	if !ok {
+		return a
+	}
+
+	bi, ok := b.(Identifiers)
+	if !ok {
+		return b
+	}
+
+	merged := slices.Clone(ai)
+
+	for _, ident := range bi {
+		i := slices.IndexFunc(merged, func(m Identifier) bool { return m.Value == ident.Value })
+		if i < 0 {
+			merged = append(merged, ident)
+
+			continue
+		}
+
+		merged[i].Child = mergeNodes(childNode(merged[i]), childNode(ident))
+	}
+
+	return merged
+}
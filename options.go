@@ -0,0 +1,93 @@
+package gofieldselect
+
+import "context"
+
+type (
+	// Option configures how a selection is applied to a value.
+	Option func(*options)
+
+	options struct {
+		deepCopy       bool
+		selectionOrder bool
+		sliceSeparator string
+		// tagKey, naming and caseInsensitive decide the selection names of the struct fields.
+		tagKey          string
+		naming          Naming
+		caseInsensitive bool
+		// columnNaming is the naming strategy of the columns when hasColumnNaming, see [SQLColumns].
+		columnNaming    Naming
+		hasColumnNaming bool
+		// policy and access decide the fields the caller can see, see [WithRoles].
+		policy FieldPolicy
+		access *access
+		// resolverConcurrency is the number of resolvers run at the same time, see [Resolve].
+		resolverConcurrency int
+	}
+)
+
+// WithDeepCopy duplicates every reachable pointer, slice and map instead of sharing them with the source.
+// Pointer cycles are detected, so self-referencing values are copied keeping their shape.
+func WithDeepCopy() Option {
+	return func(o *options) {
+		o.deepCopy = true
+	}
+}
+
+// WithSelectionOrder writes the JSON object keys in the order they appear in the selection instead of
+// the declaration order of the struct fields, e.g. `surname,name` writes `surname` first.
+// Fields selected through a wildcard keep the declaration order.
+func WithSelectionOrder() Option {
+	return func(o *options) {
+		o.selectionOrder = true
+	}
+}
+
+// WithSliceSeparator joins with sep the values of the nested slices in a single CSV cell, see [WriteCSV].
+func WithSliceSeparator(sep string) Option {
+	return func(o *options) {
+		o.sliceSeparator = sep
+	}
+}
+
+// WithTagKey uses the names in the key struct tag, e.g. `yaml`, `bson` or `db`, instead of the `json` one.
+// The options after the name, separated by a comma, are ignored and `-` ignores the field.
+// Since the `fieldselect` tag holds the selection options, its names are set with the `name` option,
+// e.g. `fieldselect:"name=fullName;always"`.
+func WithTagKey(key string) Option {
+	return func(o *options) {
+		o.tagKey = key
+	}
+}
+
+// WithNaming uses the naming strategy to get the selection name of the fields without a name in their tag,
+// e.g. [NamingCamelCase] selects the untagged field `CreatedAt` with `createdAt`.
+func WithNaming(naming Naming) Option {
+	return func(o *options) {
+		o.naming = naming
+	}
+}
+
+// WithCaseInsensitive matches the selected names with the field names ignoring the case,
+// e.g. `name` selects the untagged field `Name`.
+func WithCaseInsensitive() Option {
+	return func(o *options) {
+		o.caseInsensitive = true
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.policy != nil {
+		if o.access == nil {
+			o.access = &access{ctx: context.Background()}
+		}
+
+		o.access.policy = o.policy
+	}
+
+	return o
+}
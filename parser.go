@@ -12,6 +12,8 @@ type parser struct {
 	curToken  token.Token
 	peekToken token.Token
 	errors    []error
+	// paths is true to parse the `/` paths and `*` wildcards of the [GoogleFields] dialect.
+	paths bool
 }
 
 // New creates a new Parser based on a Lexer.
@@ -50,9 +52,20 @@ func (p *parser) nextToken() {
 // It assumes p.curToken is positioned at the first token of the list (which can be Ident, Rparen, or EOF).
 func (p *parser) parseFields() Node {
 	identifiers := make([]Identifier, 0)
+	all := false
 
 	for p.curToken.Type != token.EOF && p.curToken.Type != token.Rparen {
-		if p.curToken.Type != token.Ident {
+		switch p.curToken.Type {
+		case token.Ident:
+			n := p.parseField()
+			identifiers = append(identifiers, n)
+		case token.Wildcard:
+			// every field of the list is selected
+			all = true
+
+			p.nextToken()
+			p.expectNoPathAfterWildcard()
+		default:
 			// unexpected token; attempt to recover by skipping until next separator, rparen, or EOF
 			p.errors = append(p.errors, ErrExpectedIdentifier)
 			p.synchronize()
@@ -64,9 +77,6 @@ func (p *parser) parseFields() Node {
 			continue
 		}
 
-		n := p.parseField()
-		identifiers = append(identifiers, n)
-
 		// After a field, the current token is expected to be either ',' or ')' or EOF
 		switch p.curToken.Type {
 		case token.Separator:
@@ -74,7 +84,7 @@ func (p *parser) parseFields() Node {
 			p.nextToken()
 		case token.Rparen, token.EOF:
 			// list ends; loop condition will break
-		case token.Ident, token.Illegal, token.Lparen:
+		case token.Ident, token.Illegal, token.Lparen, token.Slash, token.Wildcard:
 			// missing comma between identifiers; record error but continue without consuming to avoid infinite loop
 			p.errors = append(p.errors, ErrMissingSeparatorBetweenIdentifiers)
 		default:
@@ -83,6 +93,20 @@ func (p *parser) parseFields() Node {
 		}
 	}
 
+	if all {
+		return AllIdentifiers{}
+	}
+
+	if p.paths {
+		// the same field can be selected by several paths, e.g. `items/id,items/name`
+		var merged Node = Identifiers{}
+		for _, ident := range identifiers {
+			merged = mergeNodes(merged, Identifiers{ident})
+		}
+
+		return merged
+	}
+
 	return Identifiers(identifiers)
 }
 
@@ -92,6 +116,23 @@ func (p *parser) parseFields() Node {
 func (p *parser) parseField() Identifier {
 	ident := Identifier{Value: p.curToken.Literal, Child: AllIdentifiers{}}
 
+	if p.peekToken.Type == token.Slash {
+		p.nextToken() // move to '/'
+		p.nextToken() // move to the next field of the path
+
+		switch p.curToken.Type {
+		case token.Ident:
+			ident.Child = Identifiers{p.parseField()}
+		case token.Wildcard:
+			p.nextToken()
+			p.expectNoPathAfterWildcard()
+		default:
+			p.errors = append(p.errors, ErrExpectedIdentifier)
+		}
+
+		return ident
+	}
+
 	if p.peekToken.Type == token.Lparen {
 		// consume '(' and move inside
 		p.nextToken() // move to '('
@@ -115,6 +156,15 @@ func (p *parser) parseField() Identifier {
 	return ident
 }
 
+// expectNoPathAfterWildcard reports a path after a wildcard, e.g. `items/*/id`, since the selections can't select
+// a field of every child.
+func (p *parser) expectNoPathAfterWildcard() {
+	if p.curToken.Type == token.Slash || p.curToken.Type == token.Lparen {
+		p.errors = append(p.errors, ErrUnexpectedWildcard)
+		p.synchronize()
+	}
+}
+
 // synchronize advances tokens until a safe point (comma, right parenthesis, or EOF).
 func (p *parser) synchronize() {
 	for p.curToken.Type != token.Separator && p.curToken.Type != token.Rparen && p.curToken.Type != token.EOF {
@@ -0,0 +1,63 @@
+package gofieldselect
+
+import (
+	"strings"
+)
+
+// pathSeparator separates the field names of a path, e.g. `address.street`.
+const pathSeparator = "."
+
+// nodePaths returns the dot separated paths of the fields selected with all their children, in selection order,
+// e.g. `name` and `address.street` for `name,address(street)`. It returns nil when every field is selected, and an
+// empty slice when none is, e.g. for `address()`, since the fields with an empty child selection have no paths.
+func nodePaths(n Node) []string {
+	identifiers, ok := n.(Identifiers)
+	if !ok {
+		return nil
+	}
+
+	paths := []string{}
+
+	for _, ident := range identifiers {
+		child := childNode(ident)
+		if isAllIdentifiers(child) {
+			paths = append(paths, ident.Value)
+
+			continue
+		}
+
+		for _, p := range nodePaths(child) {
+			paths = append(paths, ident.Value+pathSeparator+p)
+		}
+	}
+
+	return paths
+}
+
+// nodeFromPaths returns the selection of the dot separated paths, every field when there are none.
+// A path selects its field with all its children, so `address` hides `address.street`.
+func nodeFromPaths(paths []string) (Node, error) {
+	if len(paths) == 0 {
+		return AllIdentifiers{}, nil
+	}
+
+	var n Node = Identifiers{}
+
+	for _, path := range paths {
+		names := strings.Split(path, pathSeparator)
+
+		var child Node = AllIdentifiers{}
+
+		for i := len(names) - 1; i >= 0; i-- {
+			if names[i] == "" {
+				return nil, NewFieldError(path, ErrExpectedIdentifier)
+			}
+
+			child = Identifiers{{Value: names[i], Child: child}}
+		}
+
+		n = mergeNodes(n, child)
+	}
+
+	return n, nil
+}
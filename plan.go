@@ -0,0 +1,347 @@
+package gofieldselect
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+//nolint:gochecknoglobals // cache of the struct plans, shared by every call
+var plans sync.Map // map[planKey]*typePlan
+
+type (
+	// planKey identifies a plan, the same type has different selection names depending on the naming options.
+	planKey struct {
+		typ    reflect.Type
+		tagKey string
+		naming Naming
+	}
+
+	// typePlan holds the precomputed fields of a struct type, so tags are only parsed once per type.
+	typePlan struct {
+		fields []fieldPlan
+		// byName indexes the selectable fields by their selection name.
+		byName map[string]int
+		// byFoldedName indexes the selectable fields by their lower case selection name, the first one wins.
+		byFoldedName map[string]int
+		// embedded holds the index paths of the embedded struct pointers whose fields are promoted, the shallowest
+		// ones first.
+		embedded [][]int
+	}
+
+	// fieldPlan describes an exported field of a struct.
+	fieldPlan struct {
+		// name is the selection key, the tag name or the field name following the naming strategy.
+		name string
+		// field is the struct field, as declared in its struct.
+		field reflect.StructField
+		// index is the index path of the field, more than one index for fields promoted from embedded structs.
+		index []int
+		typ   reflect.Type
+		kind  reflect.Kind
+		// leaf is true if the type of the field is a leaf type.
+		leaf bool
+		// ignored is true if the field is ignored by the JSON tag, so it can't be selected by name.
+		ignored bool
+		// omitEmpty, omitZero and quoted are the `omitempty`, `omitzero` and `string` options of the JSON tag.
+		omitEmpty bool
+		omitZero  bool
+		quoted    bool
+		// tag holds the options of the `fieldselect` tag.
+		tag tagOptions
+	}
+
+	// fieldCollector collects the fields of a struct type and the ones promoted from its embedded structs.
+	fieldCollector struct {
+		key planKey
+		// embedded holds the index paths of the embedded struct pointers, see [typePlan].
+		embedded [][]int
+		// visiting holds the struct types being collected, to skip the ones embedding themselves forever.
+		visiting map[reflect.Type]struct{}
+	}
+)
+
+// planFor returns the cached plan of the struct type t with the JSON names, computing it the first time.
+func planFor(t reflect.Type) *typePlan {
+	return planForKey(planKey{typ: t, tagKey: defaultTagKey})
+}
+
+func planForKey(key planKey) *typePlan {
+	if p, ok := plans.Load(key); ok {
+		//nolint:errcheck // it's always a *typePlan
+		return p.(*typePlan)
+	}
+
+	p, _ := plans.LoadOrStore(key, newTypePlan(key))
+
+	//nolint:errcheck // it's always a *typePlan
+	return p.(*typePlan)
+}
+
+// field returns the plan of the selectable field whose selection key is name.
+func (tp *typePlan) field(name string) (fieldPlan, bool) {
+	i, ok := tp.byName[name]
+	if !ok {
+		return fieldPlan{}, false
+	}
+
+	return tp.fields[i], true
+}
+
+func newTypePlan(key planKey) *typePlan {
+	fc := fieldCollector{key: key, visiting: make(map[reflect.Type]struct{})}
+	fields := dominantFields(fc.collect(key.typ, nil))
+
+	tp := &typePlan{
+		fields:       fields,
+		embedded:     fc.embedded,
+		byName:       make(map[string]int, len(fields)),
+		byFoldedName: make(map[string]int, len(fields)),
+	}
+	for i, f := range fields {
+		if f.ignored {
+			continue
+		}
+
+		tp.byName[f.name] = i
+
+		folded := strings.ToLower(f.name)
+		if _, ok := tp.byFoldedName[folded]; !ok {
+			tp.byFoldedName[folded] = i
+		}
+	}
+
+	return tp
+}
+
+// collect returns the exported fields of t, promoting the fields of embedded structs, or pointers to structs,
+// without a tag name like encoding/json does, even when the embedded type is unexported.
+func (fc *fieldCollector) collect(t reflect.Type, index []int) []fieldPlan {
+	fc.visiting[t] = struct{}{}
+	defer delete(fc.visiting, t)
+
+	var fields []fieldPlan
+
+	for i := range t.NumField() {
+		sf := t.Field(i)
+
+		et, promoted := fc.promotedType(sf)
+		if !sf.IsExported() && !promoted {
+			continue
+		}
+
+		fieldIndex := append(slices.Clone(index), i)
+		name, ok := fieldName(sf, fc.key.tagKey, fc.key.naming)
+
+		if promoted && ok && !hasTagName(sf, fc.key.tagKey) {
+			if _, embedsItself := fc.visiting[et]; embedsItself {
+				// like encoding/json, a struct embedding itself is skipped
+				continue
+			}
+
+			if sf.Type.Kind() == reflect.Ptr {
+				fc.embedded = append(fc.embedded, fieldIndex)
+			}
+
+			fields = append(fields, fc.collect(et, fieldIndex)...)
+
+			continue
+		}
+
+		if !sf.IsExported() {
+			// an unexported embedded struct can't be set as a whole, only its promoted fields
+			continue
+		}
+
+		fp := fieldPlan{
+			name:    name,
+			field:   sf,
+			index:   fieldIndex,
+			typ:     sf.Type,
+			kind:    sf.Type.Kind(),
+			leaf:    isLeafType(sf.Type),
+			ignored: !ok,
+			tag:     parseTagOptions(sf),
+		}
+		fp.omitEmpty, fp.omitZero, fp.quoted = jsonTagOptions(sf)
+		fields = append(fields, fp)
+	}
+
+	return fields
+}
+
+// promotedType returns the struct type whose fields the embedded field sf promotes, a struct or a pointer to
+// a struct.
+func (fc *fieldCollector) promotedType(sf reflect.StructField) (reflect.Type, bool) {
+	if !sf.Anonymous {
+		return nil, false
+	}
+
+	et := sf.Type
+	if et.Kind() == reflect.Ptr {
+		et = et.Elem()
+	}
+
+	if et.Kind() != reflect.Struct || isLeafType(et) || isLeafType(sf.Type) {
+		return nil, false
+	}
+
+	return et, true
+}
+
+// fieldByIndex returns the field of the struct v at index, going through the embedded pointers.
+// It returns false when one of them is nil, so the field doesn't exist in v.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	if len(index) == 1 {
+		return v.Field(index[0]), true
+	}
+
+	f, err := v.FieldByIndexErr(index)
+
+	return f, err == nil
+}
+
+// settableFieldByIndex returns the field of the struct v at index, allocating the nil embedded pointers on the way.
+func settableFieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				settable(v).Set(reflect.New(v.Type().Elem()))
+			}
+
+			v = v.Elem()
+		}
+
+		v = v.Field(x)
+	}
+
+	return v
+}
+
+// detachEmbedded replaces the embedded struct pointers of v, a copy sharing them with its source, with pointers to
+// copies of their structs, so setting the promoted fields of v doesn't modify the source.
+func (tp *typePlan) detachEmbedded(v reflect.Value) {
+	for _, index := range tp.embedded {
+		ptr, ok := fieldByIndex(v, index)
+		if !ok || ptr.IsNil() {
+			continue
+		}
+
+		ptr = settable(ptr)
+
+		detached := reflect.New(ptr.Type().Elem())
+		detached.Elem().Set(ptr.Elem())
+		ptr.Set(detached)
+	}
+}
+
+// settable returns the addressable v as a value that can be set, even if it's an unexported embedded pointer,
+// whose promoted fields are selected like the ones of exported embedded pointers.
+func settable(v reflect.Value) reflect.Value {
+	if v.CanSet() {
+		return v
+	}
+
+	return reflect.NewAt(v.Type(), unsafe.Pointer(v.UnsafeAddr())).Elem()
+}
+
+// dominantFields removes the promoted fields hidden by others with the same name, the shallowest one wins
+// and fields with the same name at the same depth hide each other.
+func dominantFields(fields []fieldPlan) []fieldPlan {
+	depths := make(map[string][]int)
+	for _, f := range fields {
+		if !f.ignored {
+			depths[f.name] = append(depths[f.name], len(f.index))
+		}
+	}
+
+	dominant := make([]fieldPlan, 0, len(fields))
+
+	for _, f := range fields {
+		if f.ignored {
+			dominant = append(dominant, f)
+
+			continue
+		}
+
+		minDepth, count := len(f.index), 0
+		for _, d := range depths[f.name] {
+			switch {
+			case d < minDepth:
+				minDepth, count = d, 1
+			case d == minDepth:
+				count++
+			}
+		}
+
+		if minDepth == len(f.index) && count == 1 {
+			dominant = append(dominant, f)
+		}
+	}
+
+	return dominant
+}
+
+// fieldName returns the selection key of a struct field: the name in the key tag or the field name following
+// the naming strategy.
+// It returns false when the field is ignored by the tag.
+func fieldName(sf reflect.StructField, key string, naming Naming) (string, bool) {
+	name := naming.name(sf.Name)
+
+	if tag := tagName(sf, key); tag != "" {
+		tn, _, _ := strings.Cut(tag, ",")
+		if tn == "-" {
+			return "", false
+		}
+
+		if tn != "" { // explicit empty means use field name
+			name = tn
+		}
+	}
+
+	return name, true
+}
+
+// jsonTagOptions returns whether the JSON tag of the field has the `omitempty`, `omitzero` and `string` options,
+// the last one only applies to strings, booleans and numbers.
+func jsonTagOptions(sf reflect.StructField) (bool, bool, bool) {
+	_, opts, _ := strings.Cut(sf.Tag.Get("json"), ",")
+
+	var omitEmpty, omitZero, quoted bool
+
+	for opt := range strings.SplitSeq(opts, ",") {
+		switch opt {
+		case "omitempty":
+			omitEmpty = true
+		case "omitzero":
+			omitZero = true
+		case "string":
+			t := sf.Type
+			if t.Kind() == reflect.Ptr {
+				t = t.Elem()
+			}
+
+			quoted = t.Kind() == reflect.String || t.Kind() == reflect.Bool || isNumberKind(t.Kind())
+		}
+	}
+
+	return omitEmpty, omitZero, quoted
+}
+
+func hasTagName(sf reflect.StructField, key string) bool {
+	name, _, _ := strings.Cut(tagName(sf, key), ",")
+
+	return name != ""
+}
+
+// tagName returns the value of the key tag of the field with its name, the `name` option for the `fieldselect`
+// tag since the rest of its options aren't names.
+func tagName(sf reflect.StructField, key string) string {
+	if key == tagKey {
+		return parseTagOptions(sf).name
+	}
+
+	return sf.Tag.Get(key)
+}
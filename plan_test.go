@@ -0,0 +1,222 @@
+package gofieldselect
+
+import (
+	"encoding/json"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+type Base struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type Customer struct {
+	Base
+
+	Name    string `json:"name"`
+	Email   string `json:"email"`
+	Secret  string `json:"-"`
+	private string
+}
+
+func TestPlanForPromotesEmbeddedFields(t *testing.T) {
+	t.Parallel()
+
+	tp := planFor(reflect.TypeFor[Customer]())
+
+	id, ok := tp.field("id")
+	if !ok {
+		t.Fatalf("expected promoted field id")
+	}
+
+	if !reflect.DeepEqual(id.index, []int{0, 0}) {
+		t.Fatalf("expected id index [0 0]; got %v", id.index)
+	}
+
+	name, ok := tp.field("name")
+	if !ok || !reflect.DeepEqual(name.index, []int{1}) {
+		t.Fatalf("expected the shallowest name field; got %+v", name)
+	}
+
+	if _, ok = tp.field("Secret"); ok {
+		t.Fatalf("expected ignored field not to be selectable")
+	}
+
+	if _, ok = tp.field("private"); ok {
+		t.Fatalf("expected unexported field not to be selectable")
+	}
+}
+
+func TestPlanForIsCachedConcurrently(t *testing.T) {
+	t.Parallel()
+
+	typ := reflect.TypeFor[UserPtr]()
+	got := make([]*typePlan, 8)
+
+	var wg sync.WaitGroup
+	for i := range got {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			got[i] = planFor(typ)
+		}()
+	}
+
+	wg.Wait()
+
+	for _, tp := range got {
+		if tp != got[0] {
+			t.Fatalf("expected the same cached plan")
+		}
+	}
+}
+
+func TestGetWithReflectionEmbeddedFields(t *testing.T) {
+	t.Parallel()
+
+	src := Customer{Base: Base{ID: 1, Name: "base"}, Name: "John", Email: "john@example.com"}
+
+	got, err := GetWithReflection(parse(t, "id,name"), src)
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	expected := Customer{Base: Base{ID: 1}, Name: "John"}
+	if got != expected {
+		t.Fatalf("expected %+v; got %+v", expected, got)
+	}
+}
+
+func TestEmbeddedPointerFields(t *testing.T) {
+	t.Parallel()
+
+	type Supplier struct {
+		*Base
+
+		Email string `json:"email"`
+	}
+
+	if id, ok := planFor(reflect.TypeFor[Supplier]()).field("id"); !ok || !reflect.DeepEqual(id.index, []int{0, 0}) {
+		t.Fatalf("expected id promoted from the embedded pointer; got %+v", id)
+	}
+
+	src := Supplier{Base: &Base{ID: 1, Name: "base"}, Email: "john@example.com"}
+
+	got, err := GetWithReflection(parse(t, "id"), src)
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	if got.Base == nil || got.Base == src.Base || got.ID != 1 || got.Name != "" || got.Email != "" {
+		t.Fatalf("expected a new base with only the id; got %+v", got)
+	}
+
+	got, err = GetWithReflection(parse(t, ""), src, WithDeepCopy())
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	if got.Base == src.Base || *got.Base != *src.Base {
+		t.Fatalf("expected the base to be duplicated; got %+v", got.Base)
+	}
+
+	b, err := Marshal(parse(t, "id,name,email"), src)
+	if err != nil || string(b) != `{"id":1,"name":"base","email":"john@example.com"}` {
+		t.Fatalf("expected the promoted fields, got %s, %v", b, err)
+	}
+
+	if b, err = Marshal(parse(t, "id,email"), Supplier{Email: "a"}); err != nil || string(b) != `{"email":"a"}` {
+		t.Fatalf("expected the fields of a nil embedded pointer to be omitted, got %s, %v", b, err)
+	}
+
+	if got, err = GetWithReflection(parse(t, "id,email"), Supplier{Email: "a"}); err != nil || got.Base != nil {
+		t.Fatalf("expected a nil embedded pointer to stay nil, got %+v, %v", got, err)
+	}
+
+	type Chain struct {
+		*Chain
+
+		Value int `json:"value"`
+	}
+
+	if fields := planFor(reflect.TypeFor[Chain]()).fields; len(fields) != 1 || fields[0].name != "value" {
+		t.Fatalf("expected a struct embedding itself to be skipped; got %+v", fields)
+	}
+}
+
+type (
+	creds struct {
+		Token string `json:"token"`
+	}
+
+	meta struct {
+		Version int `json:"version"`
+	}
+
+	// acct promotes the fields of unexported embedded types, like encoding/json does.
+	acct struct {
+		creds
+		*meta
+
+		Name string `json:"name"`
+	}
+)
+
+func TestUnexportedEmbeddedFields(t *testing.T) {
+	t.Parallel()
+
+	tp := planFor(reflect.TypeFor[acct]())
+	if token, ok := tp.field("token"); !ok || !reflect.DeepEqual(token.index, []int{0, 0}) {
+		t.Fatalf("expected token promoted from the unexported struct; got %+v", token)
+	}
+
+	if version, ok := tp.field("version"); !ok || !reflect.DeepEqual(version.index, []int{1, 0}) {
+		t.Fatalf("expected version promoted from the unexported pointer; got %+v", version)
+	}
+
+	if err := Validate[acct](parse(t, "token,version,name")); err != nil {
+		t.Fatalf("expected the promoted fields to be valid, got %v", err)
+	}
+
+	src := acct{creds: creds{Token: "s3cret"}, meta: &meta{Version: 2}, Name: "n"}
+
+	expected, err := json.Marshal(src)
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	if got, err := Marshal(nil, src); err != nil || string(got) != string(expected) {
+		t.Fatalf("expected %s, got %s, %v", expected, got, err)
+	}
+
+	applied := acct{creds: creds{Token: "s3cret"}, meta: &meta{Version: 2}, Name: "n"}
+	if err = Apply(parse(t, "name"), &applied); err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	if applied.Token != "" || applied.Version != 0 || applied.Name != "n" {
+		t.Fatalf("expected only the name kept, got %+v %+v", applied, applied.meta)
+	}
+
+	got, err := GetWithReflection(parse(t, "version"), src)
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	if got.meta == nil || got.meta == src.meta || got.Version != 2 || got.Token != "" || got.Name != "" {
+		t.Fatalf("expected a new meta with only the version, got %+v", got)
+	}
+
+	if got, err = GetWithReflection(parse(t, ""), src, WithDeepCopy()); err != nil || got.meta == src.meta ||
+		*got.meta != *src.meta || got.Token != "s3cret" {
+		t.Fatalf("expected the unexported pointer duplicated, got %+v, %v", got, err)
+	}
+
+	if got, err = GetWithReflection(parse(t, "name,version"), acct{Name: "n"}); err != nil || got.meta != nil {
+		t.Fatalf("expected a nil unexported pointer to stay nil, got %+v, %v", got, err)
+	}
+}
@@ -0,0 +1,329 @@
+package gofieldselect
+
+import (
+	"math"
+	"reflect"
+)
+
+type (
+	// projector copies values between different types applying a selection, matching struct fields by name.
+	projector struct {
+		opts options
+		// visited holds the projections of the pointers already reached with a wildcard selection, to break cycles.
+		visited map[projectKey]reflect.Value
+		// ignoreFrom matches the fields only by name, for types whose tags were copied from the source type.
+		ignoreFrom bool
+	}
+
+	projectKey struct {
+		ptr uintptr
+		src reflect.Type
+		dst reflect.Type
+	}
+)
+
+// Project creates a new instance of [Dst] with only the fields specified in [n], taking the values from the fields
+// of [src] with the same selection name, or the one set in the `fieldselect:"from=..."` tag of the [Dst] field.
+// Values and pointers are converted into each other and nested structs, slices and maps are projected recursively,
+// e.g. from a DAO to a DTO.
+// The selection names are the ones of [Dst], and both types follow the [WithTagKey], [WithNaming] and
+// [WithCaseInsensitive] options.
+func Project[Src, Dst any](n Node, src Src, opts ...Option) (Dst, error) {
+	var selected Dst
+
+	st, dt := reflect.TypeFor[Src](), reflect.TypeFor[Dst]()
+	if !isStructOrPtrToStruct(st) {
+		return selected, NewTypeNotValidError(st.Kind())
+	}
+
+	if !isStructOrPtrToStruct(dt) {
+		return selected, NewTypeNotValidError(dt.Kind())
+	}
+
+	p := projector{opts: newOptions(opts)}
+	if err := p.projectValue(n, reflect.ValueOf(&src).Elem(), reflect.ValueOf(&selected).Elem()); err != nil {
+		var zero Dst
+
+		return zero, err
+	}
+
+	return selected, nil
+}
+
+// projectValue sets in dst the value of src, converting it to the type of dst, with only the fields in node.
+//
+//nolint:exhaustive // the rest of kinds are converted
+func (p *projector) projectValue(node Node, src, dst reflect.Value) error {
+	st, dt := src.Type(), dst.Type()
+	if st == dt {
+		c := copier{opts: p.opts}
+
+		return c.copyValue(node, src, dst)
+	}
+
+	switch {
+	case st.Kind() == reflect.Ptr:
+		if src.IsNil() {
+			dst.SetZero()
+
+			return nil
+		}
+
+		return p.projectValue(node, src.Elem(), dst)
+	case dt.Kind() == reflect.Ptr:
+		return p.projectPtr(node, src, dst)
+	case st.Kind() == reflect.Interface:
+		if src.IsNil() {
+			dst.SetZero()
+
+			return nil
+		}
+
+		return p.projectValue(node, src.Elem(), dst)
+	}
+
+	if isLeafType(st) || isLeafType(dt) {
+		if !isAllIdentifiers(node) {
+			return ErrChildSelectionOnLeaf
+		}
+
+		return convertValue(src, dst)
+	}
+
+	switch {
+	case st.Kind() == reflect.Struct && dt.Kind() == reflect.Struct:
+		return p.projectStruct(node, src, dst)
+	case (st.Kind() == reflect.Slice || st.Kind() == reflect.Array) && dt.Kind() == reflect.Slice:
+		if st.Kind() == reflect.Slice && src.IsNil() {
+			dst.SetZero()
+
+			return nil
+		}
+
+		dst.Set(reflect.MakeSlice(dt, src.Len(), src.Len()))
+
+		return p.projectElements(node, src, dst)
+	case (st.Kind() == reflect.Slice || st.Kind() == reflect.Array) && dt.Kind() == reflect.Array:
+		if src.Len() != dst.Len() {
+			return ErrIncompatibleTypes
+		}
+
+		return p.projectElements(node, src, dst)
+	case st.Kind() == reflect.Map && dt.Kind() == reflect.Map:
+		return p.projectMap(node, src, dst)
+	default:
+		return convertValue(src, dst)
+	}
+}
+
+// projectPtr projects src into a new value pointed by dst.
+func (p *projector) projectPtr(node Node, src, dst reflect.Value) error {
+	all := isAllIdentifiers(node)
+
+	var key projectKey
+	if all && src.CanAddr() {
+		key = projectKey{ptr: src.Addr().Pointer(), src: src.Type(), dst: dst.Type()}
+		if v, ok := p.visited[key]; ok {
+			dst.Set(v)
+
+			return nil
+		}
+	}
+
+	ptr := reflect.New(dst.Type().Elem())
+	if all && src.CanAddr() {
+		if p.visited == nil {
+			p.visited = make(map[projectKey]reflect.Value)
+		}
+
+		p.visited[key] = ptr
+	}
+
+	dst.Set(ptr)
+
+	return p.projectValue(node, src, ptr.Elem())
+}
+
+func (p *projector) projectStruct(node Node, src, dst reflect.Value) error {
+	srcPlan := p.opts.planFor(src.Type())
+
+	for _, fp := range p.opts.planFor(dst.Type()).fields {
+		if fp.ignored {
+			continue
+		}
+
+		child, ok := p.opts.selectedField(node, dst.Type(), fp)
+		if !ok {
+			continue
+		}
+
+		from := fp.name
+		if fp.tag.from != "" && !p.ignoreFrom {
+			from = fp.tag.from
+		}
+
+		sfp, ok := p.opts.field(srcPlan, from)
+		if !ok || sfp.tag.mode == modeNever || !p.opts.allowed(src.Type(), sfp) {
+			// the source doesn't have it or can't give it, keep the default value
+			continue
+		}
+
+		sf, exists := fieldByIndex(src, sfp.index)
+		if !exists {
+			continue
+		}
+
+		if err := p.projectValue(child, sf, settableFieldByIndex(dst, fp.index)); err != nil {
+			return wrapFieldError(fp.name, err)
+		}
+	}
+
+	return nil
+}
+
+func (p *projector) projectElements(node Node, src, dst reflect.Value) error {
+	for i := range src.Len() {
+		if err := p.projectValue(node, src.Index(i), dst.Index(i)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// projectMap projects the entries of a map, when its keys are strings they are filtered by the selection.
+func (p *projector) projectMap(node Node, src, dst reflect.Value) error {
+	if src.IsNil() {
+		dst.SetZero()
+
+		return nil
+	}
+
+	dt := dst.Type()
+	stringKeys := src.Type().Key().Kind() == reflect.String
+	m := reflect.MakeMapWithSize(dt, src.Len())
+
+	iter := src.MapRange()
+	for iter.Next() {
+		child := node
+
+		if stringKeys {
+			ident, ok := p.opts.selectField(node, iter.Key().String())
+			if !ok {
+				continue
+			}
+
+			child = childNode(ident)
+		}
+
+		key := reflect.New(dt.Key()).Elem()
+		if err := convertValue(iter.Key(), key); err != nil {
+			return err
+		}
+
+		v := reflect.New(dt.Elem()).Elem()
+		if err := p.projectValue(child, iter.Value(), v); err != nil {
+			if stringKeys {
+				return wrapFieldError(iter.Key().String(), err)
+			}
+
+			return err
+		}
+
+		m.SetMapIndex(key, v)
+	}
+
+	dst.Set(m)
+
+	return nil
+}
+
+// convertValue sets src in dst if it is assignable, or converts it if both are of the same kind or numbers,
+// e.g. from int32 to int64.
+// Numbers that overflow the type of dst, or lose their fractional part, return [ErrIncompatibleTypes].
+func convertValue(src, dst reflect.Value) error {
+	st, dt := src.Type(), dst.Type()
+
+	switch {
+	case st.AssignableTo(dt):
+		dst.Set(src)
+	case isNumberKind(st.Kind()) && isNumberKind(dt.Kind()):
+		if !numberFits(src, dst) {
+			return ErrIncompatibleTypes
+		}
+
+		dst.Set(src.Convert(dt))
+	case st.Kind() == dt.Kind() && st.ConvertibleTo(dt):
+		dst.Set(src.Convert(dt))
+	default:
+		return ErrIncompatibleTypes
+	}
+
+	return nil
+}
+
+// numberFits reports whether the number src can be converted to the number type of dst without changing its value,
+// besides the rounding of floats.
+//
+//nolint:exhaustive // only number kinds are converted
+func numberFits(src, dst reflect.Value) bool {
+	switch src.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return intFits(src.Int(), dst)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u := src.Uint()
+		if isFloatKind(dst.Kind()) {
+			return true
+		}
+
+		if isIntKind(dst.Kind()) {
+			return u <= math.MaxInt64 && !dst.OverflowInt(int64(u))
+		}
+
+		return !dst.OverflowUint(u)
+	default:
+		f := src.Float()
+		if isFloatKind(dst.Kind()) {
+			return math.IsNaN(f) || math.IsInf(f, 0) || !dst.OverflowFloat(f)
+		}
+
+		// the bounds are powers of two, so they're exact as floats
+		if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxUint64 {
+			return false
+		}
+
+		if f < math.MaxInt64 {
+			return intFits(int64(f), dst)
+		}
+
+		return isUintKind(dst.Kind()) && !dst.OverflowUint(uint64(f))
+	}
+}
+
+// intFits reports whether the integer i can be converted to the number type of dst without changing its value.
+func intFits(i int64, dst reflect.Value) bool {
+	switch {
+	case isIntKind(dst.Kind()):
+		return !dst.OverflowInt(i)
+	case isUintKind(dst.Kind()):
+		return i >= 0 && !dst.OverflowUint(uint64(i))
+	default:
+		return true
+	}
+}
+
+func isIntKind(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Int64
+}
+
+func isUintKind(k reflect.Kind) bool {
+	return k >= reflect.Uint && k <= reflect.Uintptr
+}
+
+func isFloatKind(k reflect.Kind) bool {
+	return k == reflect.Float32 || k == reflect.Float64
+}
+
+func isNumberKind(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Float64
+}
@@ -0,0 +1,159 @@
+package gofieldselect
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+type (
+	userDao struct {
+		Name     string           `json:"name"`
+		LastName string           `json:"lastName"`
+		Age      int32            `json:"age"`
+		Address  addressDao       `json:"address"`
+		Previous []addressDao     `json:"previous"`
+		Friend   *userDao         `json:"friend"`
+		Extra    map[string]int32 `json:"extra"`
+	}
+
+	addressDao struct {
+		Street string `json:"street"`
+		Number int    `json:"number"`
+	}
+
+	userDto struct {
+		Name     string           `json:"name"`
+		Surname  string           `json:"surname"  fieldselect:"from=lastName"`
+		Age      int64            `json:"age"`
+		Address  *Address         `json:"address"`
+		Previous []Address        `json:"previous"`
+		Friend   *userDto         `json:"friend"`
+		Extra    map[string]int64 `json:"extra"`
+		Missing  string           `json:"missing"`
+	}
+)
+
+func TestProject(t *testing.T) {
+	t.Parallel()
+
+	src := userDao{
+		Name:     "John",
+		LastName: "Doe",
+		Age:      30,
+		Address:  addressDao{Street: "Main", Number: 1},
+		Previous: []addressDao{{Street: "Old", Number: 2}},
+		Extra:    map[string]int32{"a": 1, "b": 2},
+	}
+
+	tests := map[string]struct {
+		selection string
+		expected  userDto
+	}{
+		"renamed and converted fields": {
+			selection: "name,surname,age",
+			expected:  userDto{Name: "John", Surname: "Doe", Age: 30},
+		},
+		"value to pointer": {
+			selection: "address(street)",
+			expected:  userDto{Address: &Address{Street: "Main"}},
+		},
+		"slices and maps": {
+			selection: "previous(number),extra(b)",
+			expected:  userDto{Previous: []Address{{Number: 2}}, Extra: map[string]int64{"b": 2}},
+		},
+		"all fields": {
+			selection: "",
+			expected: userDto{
+				Name:     "John",
+				Surname:  "Doe",
+				Age:      30,
+				Address:  &Address{Street: "Main", Number: 1},
+				Previous: []Address{{Street: "Old", Number: 2}},
+				Extra:    map[string]int64{"a": 1, "b": 2},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := Project[userDao, userDto](parse(t, tc.selection), src)
+			if err != nil {
+				t.Fatalf("error: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Fatalf("expected %+v; got %+v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestProjectCycle(t *testing.T) {
+	t.Parallel()
+
+	src := &userDao{Name: "John"}
+	src.Friend = &userDao{Name: "Jane", Friend: src}
+
+	got, err := Project[*userDao, *userDto](parse(t, ""), src)
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	if got.Friend.Name != "Jane" || got.Friend.Friend != got {
+		t.Fatalf("expected the cycle to be kept; got %+v", got)
+	}
+}
+
+func TestProjectIncompatibleTypes(t *testing.T) {
+	t.Parallel()
+
+	type dst struct {
+		Name int `json:"name"`
+	}
+
+	_, err := Project[userDao, dst](parse(t, "name"), userDao{Name: "John"})
+	if !errors.Is(err, ErrIncompatibleTypes) {
+		t.Fatalf("expected ErrIncompatibleTypes; got %v", err)
+	}
+}
+
+func TestConvertValueNumbers(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		src  any
+		dst  any
+		fits bool
+	}{
+		{src: int64(127), dst: int8(0), fits: true},
+		{src: int64(128), dst: int8(0), fits: false},
+		{src: int64(-1), dst: uint(0), fits: false},
+		{src: uint64(math.MaxUint64), dst: int64(0), fits: false},
+		{src: uint8(255), dst: int16(0), fits: true},
+		{src: 2.0, dst: 0, fits: true},
+		{src: 2.5, dst: 0, fits: false},
+		{src: math.NaN(), dst: 0, fits: false},
+		{src: float64(math.MaxUint32), dst: uint32(0), fits: true},
+		{src: 1e20, dst: uint64(0), fits: false},
+		{src: math.MaxFloat64, dst: float32(0), fits: false},
+		{src: 1.5, dst: float32(0), fits: true},
+		{src: int32(3), dst: 0.0, fits: true},
+	}
+
+	for _, tt := range tests {
+		dst := reflect.New(reflect.TypeOf(tt.dst)).Elem()
+
+		err := convertValue(reflect.ValueOf(tt.src), dst)
+		if tt.fits && err != nil {
+			t.Fatalf("%T(%v) to %T: error: %v", tt.src, tt.src, tt.dst, err)
+		}
+
+		if !tt.fits && !errors.Is(err, ErrIncompatibleTypes) {
+			t.Fatalf("%T(%v) to %T: expected ErrIncompatibleTypes, got %v", tt.src, tt.src, tt.dst, err)
+		}
+	}
+}
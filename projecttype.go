@@ -0,0 +1,173 @@
+package gofieldselect
+
+import (
+	"reflect"
+	"strconv"
+	"sync"
+)
+
+//nolint:gochecknoglobals // cache of the projected types, shared by every call
+var projectedTypes sync.Map // map[projectedTypeKey]reflect.Type
+
+type (
+	// projectedTypeKey identifies a projected type, the selection names depend on the naming options.
+	projectedTypeKey struct {
+		plan            planKey
+		caseInsensitive bool
+		selection       string
+	}
+
+	// typeBuilder builds the projected types of a call.
+	typeBuilder struct {
+		opts options
+		// building holds the types being projected, to find the recursive ones.
+		building map[projectedTypeKey]struct{}
+		// err is set when a projection refers to itself, which [reflect.StructOf] can't build.
+		err error
+	}
+)
+
+// ProjectType returns an anonymous struct type, built with [reflect.StructOf], with only the fields of [T]
+// specified in [n], keeping their original tags. Since unselected fields don't exist in it, encoding/json and any
+// other reflection based encoder omit them without needing `omitempty`.
+// Nested selections are projected too, through pointers, slices and arrays, while maps and leaf types are kept.
+// [T] must be a struct or a pointer to a struct, otherwise nil is returned.
+// A wildcard selection keeps the type as it is unless it reaches `explicit` or `never` fields, which are removed.
+// The projection of a recursive type that removes fields can't refer to itself, so nil is returned for it.
+// The projected types are cached by type, selection and naming options, see [WithTagKey], unless they depend on
+// the caller, see [WithRoles].
+func ProjectType[T any](n Node, opts ...Option) reflect.Type {
+	t := reflect.TypeFor[T]()
+	if !isStructOrPtrToStruct(t) {
+		return nil
+	}
+
+	b := typeBuilder{opts: newOptions(opts)}
+
+	pt := b.projectedType(n, t)
+	if b.err != nil {
+		return nil
+	}
+
+	return pt
+}
+
+// GetProjected returns a value of the type [ProjectType] returns for [T] and [n], with the selected fields copied
+// from [source] like [GetWithReflection] does.
+// It returns [ErrRecursiveType] when the projection of a recursive type removes fields.
+func GetProjected[T any](n Node, source T, opts ...Option) (any, error) {
+	t := reflect.TypeFor[T]()
+	if !isStructOrPtrToStruct(t) {
+		return nil, NewTypeNotValidError(t.Kind())
+	}
+
+	b := typeBuilder{opts: newOptions(opts)}
+
+	pt := b.projectedType(n, t)
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	dst := reflect.New(pt).Elem()
+
+	p := projector{opts: b.opts, ignoreFrom: true}
+	if err := p.projectValue(n, reflect.ValueOf(&source).Elem(), dst); err != nil {
+		return nil, err
+	}
+
+	return dst.Interface(), nil
+}
+
+// projectedType returns the cached projection of t with the selection node, not cached when it depends on the
+// caller, see [WithRoles].
+func (b *typeBuilder) projectedType(node Node, t reflect.Type) reflect.Type {
+	key := projectedTypeKey{
+		plan:            planKey{typ: t, tagKey: b.opts.nameTagKey(), naming: b.opts.naming},
+		caseInsensitive: b.opts.caseInsensitive,
+		selection:       canonical(node),
+	}
+
+	cached := b.opts.access == nil
+	if pt, ok := projectedTypes.Load(key); ok && cached {
+		//nolint:errcheck // it's always a reflect.Type
+		return pt.(reflect.Type)
+	}
+
+	if _, ok := b.building[key]; ok {
+		// a recursive type, it can't refer to its own projection
+		b.err = ErrRecursiveType
+
+		return t
+	}
+
+	if b.building == nil {
+		b.building = make(map[projectedTypeKey]struct{})
+	}
+
+	b.building[key] = struct{}{}
+	built := b.buildProjectedType(node, t)
+	delete(b.building, key)
+
+	if !cached || b.err != nil {
+		return built
+	}
+
+	pt, _ := projectedTypes.LoadOrStore(key, built)
+
+	//nolint:errcheck // it's always a reflect.Type
+	return pt.(reflect.Type)
+}
+
+//nolint:exhaustive // the rest of kinds are kept as they are
+func (b *typeBuilder) buildProjectedType(node Node, t reflect.Type) reflect.Type {
+	if isLeafType(t) || (isAllIdentifiers(node) && !b.opts.restricted(t)) {
+		return t
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return reflect.PointerTo(b.projectedType(node, t.Elem()))
+	case reflect.Slice:
+		return reflect.SliceOf(b.projectedType(node, t.Elem()))
+	case reflect.Array:
+		return reflect.ArrayOf(t.Len(), b.projectedType(node, t.Elem()))
+	case reflect.Struct:
+		return b.buildProjectedStruct(node, t)
+	default:
+		return t
+	}
+}
+
+// buildProjectedStruct returns a struct type with the selected fields of t, promoted fields included.
+func (b *typeBuilder) buildProjectedStruct(node Node, t reflect.Type) reflect.Type {
+	var fields []reflect.StructField
+
+	names := make(map[string]int)
+
+	for _, fp := range b.opts.planFor(t).fields {
+		if fp.ignored {
+			continue
+		}
+
+		child, ok := b.opts.selectedField(node, t, fp)
+		if !ok {
+			continue
+		}
+
+		// promoted fields may share their Go name with other fields
+		name := fp.field.Name
+		if count := names[name]; count > 0 {
+			name += strconv.Itoa(count)
+		}
+
+		names[fp.field.Name]++
+
+		fields = append(fields, reflect.StructField{
+			Name: name,
+			Type: b.projectedType(child, fp.typ),
+			Tag:  fp.field.Tag,
+		})
+	}
+
+	return reflect.StructOf(fields)
+}
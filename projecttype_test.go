@@ -0,0 +1,161 @@
+package gofieldselect
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestProjectTypeFields(t *testing.T) {
+	t.Parallel()
+
+	pt := ProjectType[Article](parse(t, "title,body,author(address(street)),id"))
+	if pt == nil || pt.Kind() != reflect.Struct {
+		t.Fatalf("expected a struct type, got %v", pt)
+	}
+
+	if pt.NumField() != 4 {
+		t.Fatalf("expected 4 fields, got %d", pt.NumField())
+	}
+
+	body, ok := pt.FieldByName("Body")
+	if !ok || body.Tag.Get("json") != "body,omitempty" {
+		t.Fatalf("expected the original tag of Body, got %+v", body)
+	}
+
+	author, ok := pt.FieldByName("Author")
+	if !ok || author.Type.Kind() != reflect.Ptr {
+		t.Fatalf("expected a pointer Author field, got %+v", author)
+	}
+
+	address, ok := author.Type.Elem().FieldByName("Address")
+	if !ok || address.Type.Elem().NumField() != 1 {
+		t.Fatalf("expected a projected Address with 1 field, got %+v", address)
+	}
+
+	if _, ok = pt.FieldByName("Views"); ok {
+		t.Fatalf("expected no Views field")
+	}
+}
+
+func TestProjectTypePromotedFields(t *testing.T) {
+	t.Parallel()
+
+	pt := ProjectType[*Customer](parse(t, "id,name"))
+	if pt.Kind() != reflect.Ptr || pt.Elem().NumField() != 2 {
+		t.Fatalf("expected a pointer to a struct with 2 fields, got %v", pt)
+	}
+}
+
+func TestProjectTypeNotStruct(t *testing.T) {
+	t.Parallel()
+
+	if pt := ProjectType[[]Article](parse(t, "id")); pt != nil {
+		t.Fatalf("expected nil, got %v", pt)
+	}
+}
+
+func TestProjectTypeCached(t *testing.T) {
+	t.Parallel()
+
+	first := ProjectType[User](parse(t, "name,address(number,street)"))
+	second := ProjectType[User](parse(t, "address(street,number),name,name"))
+
+	if first != second {
+		t.Fatalf("expected the same type for equivalent selections")
+	}
+
+	if all := ProjectType[User](parse(t, "")); all != reflect.TypeFor[User]() {
+		t.Fatalf("expected the original type, got %v", all)
+	}
+}
+
+func TestGetProjected(t *testing.T) {
+	t.Parallel()
+
+	a := newArticle()
+	a.Author.Address = nil
+
+	v, err := GetProjected(parse(t, "id,title,views,author(name,address(street)),tags"), a)
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	got, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	expected := `{"id":"7","title":"Hello","views":0,"author":{"name":"John","address":null},"tags":["go"]}`
+	if string(got) != expected {
+		t.Fatalf("expected %s, got %s", expected, got)
+	}
+}
+
+func TestGetProjectedIgnoresFromTag(t *testing.T) {
+	t.Parallel()
+
+	v, err := GetProjected(parse(t, "name,surname"), userDto{Name: "John", Surname: "Doe", Age: 30})
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	got, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	expected := `{"name":"John","surname":"Doe"}`
+	if string(got) != expected {
+		t.Fatalf("expected %s, got %s", expected, got)
+	}
+}
+
+func TestProjectTypeRecursive(t *testing.T) {
+	t.Parallel()
+
+	type rec struct {
+		Name   string `json:"name"`
+		Secret string `json:"secret" fieldselect:"never"`
+		Kids   []rec  `json:"kids"`
+	}
+
+	if pt := ProjectType[rec](parse(t, "")); pt != nil {
+		t.Fatalf("expected nil for a recursive type removing fields, got %v", pt)
+	}
+
+	if _, err := GetProjected(parse(t, ""), rec{Name: "n", Secret: "s", Kids: []rec{{Secret: "s"}}}); !errors.Is(
+		err, ErrRecursiveType) {
+		t.Fatalf("expected %v, got %v", ErrRecursiveType, err)
+	}
+
+	// the wildcard removes the explicit and never fields, or the ones the caller isn't allowed to see
+	if _, err := GetProjected(AllIdentifiers{}, newAccount()); !errors.Is(err, ErrRecursiveType) {
+		t.Fatalf("expected %v, got %v", ErrRecursiveType, err)
+	}
+
+	if _, err := GetProjected(AllIdentifiers{}, newMember(), WithRoles(t.Context(), "owner")); !errors.Is(
+		err, ErrRecursiveType) {
+		t.Fatalf("expected %v, got %v", ErrRecursiveType, err)
+	}
+
+	v, err := GetProjected(parse(t, "name,kids(name)"), rec{Name: "n", Secret: "s", Kids: []rec{{Name: "k"}}})
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	got, err := json.Marshal(v)
+	if err != nil || string(got) != `{"name":"n","kids":[{"name":"k"}]}` {
+		t.Fatalf("expected the finite selection to be projected, got %s, %v", got, err)
+	}
+
+	type tree struct {
+		Name string  `json:"name"`
+		Kids []*tree `json:"kids"`
+	}
+
+	if pt := ProjectType[tree](parse(t, "")); pt != reflect.TypeFor[tree]() {
+		t.Fatalf("expected the original type without restricted fields, got %v", pt)
+	}
+}
@@ -0,0 +1,314 @@
+package gofieldselect
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// defaultResolverConcurrency is the number of resolvers run at the same time by default, see
+// [WithResolverConcurrency].
+const defaultResolverConcurrency = 10
+
+//nolint:gochecknoglobals // registry of the resolvers, shared by every call
+var resolvers = struct {
+	sync.RWMutex
+	// byType holds the resolvers of each struct type by the index of their field, so they are found with any naming.
+	byType map[reflect.Type]map[string]resolver
+}{byType: make(map[reflect.Type]map[string]resolver)}
+
+type (
+	// ResolverOption configures a resolver, see [RegisterResolver].
+	ResolverOption func(*resolver)
+
+	resolver struct {
+		fn      func(ctx context.Context, v reflect.Value) (any, error)
+		timeout time.Duration
+	}
+
+	// resolveTask is a selected field with a resolver, found while going through the projected value.
+	resolveTask struct {
+		path string
+		node Node
+		r    resolver
+		// src is the source struct passed to the resolver and dst the projected field the result is set to.
+		src reflect.Value
+		dst reflect.Value
+	}
+
+	resolveResult struct {
+		value any
+		err   error
+	}
+
+	// resolveCollector finds the resolve tasks of a projected value.
+	resolveCollector struct {
+		opts  options
+		tasks []resolveTask
+		// visiting holds the pointers being gone through with a wildcard selection, to break cycles.
+		visiting map[visitKey]struct{}
+	}
+)
+
+// WithResolverTimeout cancels the context passed to the resolver, and fails it, when it takes longer than d.
+func WithResolverTimeout(d time.Duration) ResolverOption {
+	return func(r *resolver) {
+		r.timeout = d
+	}
+}
+
+// WithResolverConcurrency runs at most n resolvers at the same time in [Resolve], 10 by default.
+func WithResolverConcurrency(n int) Option {
+	return func(o *options) {
+		o.resolverConcurrency = n
+	}
+}
+
+// RegisterResolver registers fn to compute the field with the selection name jsonName of the struct [T].
+// The resolver is only called by [Resolve] when the field is selected, with the source value the field belongs to,
+// and its result is set to the field.
+// It panics if [T] is not a struct or it doesn't have a field named jsonName.
+func RegisterResolver[T any](jsonName string, fn func(ctx context.Context, v T) (any, error), opts ...ResolverOption) {
+	t := reflect.TypeFor[T]()
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("gofieldselect: resolver for %s, it must be a struct", t))
+	}
+
+	fp, ok := planFor(t).field(jsonName)
+	if !ok {
+		panic(fmt.Sprintf("gofieldselect: resolver for the unknown field %q of %s", jsonName, t))
+	}
+
+	r := resolver{
+		fn: func(ctx context.Context, v reflect.Value) (any, error) {
+			//nolint:errcheck // it's always T
+			return fn(ctx, v.Interface().(T))
+		},
+	}
+	for _, opt := range opts {
+		opt(&r)
+	}
+
+	resolvers.Lock()
+	defer resolvers.Unlock()
+
+	if resolvers.byType[t] == nil {
+		resolvers.byType[t] = make(map[string]resolver)
+	}
+
+	resolvers.byType[t][fieldKey(fp.index)] = r
+}
+
+// Resolve creates a new instance of [T] with only the fields specified in [n], like [GetWithReflection] does,
+// and sets the selected fields with a resolver, see [RegisterResolver], to their result.
+// The resolvers run concurrently, at most 10 at the same time unless [WithResolverConcurrency] is given, and every
+// error is returned in a [ResolveError], with the path of the field.
+// A resolver fails as soon as its context is done, but if it doesn't check it, it keeps running in the background
+// until it returns, and its result is discarded.
+// The value is deep copied, so setting the resolved fields never changes [v].
+func Resolve[T any](ctx context.Context, n Node, v T, opts ...Option) (T, error) {
+	var zero T
+
+	if err := ctx.Err(); err != nil {
+		return zero, err
+	}
+
+	o := newOptions(opts)
+	o.deepCopy = true
+
+	selected, err := getWithReflection(n, v, o)
+	if err != nil {
+		return zero, err
+	}
+
+	rc := resolveCollector{opts: o}
+	rc.collect(n, reflect.ValueOf(&v).Elem(), reflect.ValueOf(&selected).Elem(), "")
+
+	if len(rc.tasks) == 0 {
+		return selected, nil
+	}
+
+	results := runResolvers(ctx, rc.tasks, o.resolverConcurrency)
+
+	var errs []error
+
+	for i, task := range rc.tasks {
+		if err = results[i].err; err == nil {
+			err = setResolved(o, task, results[i].value)
+		}
+
+		if err != nil {
+			errs = append(errs, NewFieldError(task.path, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return zero, NewResolveError(errs)
+	}
+
+	return selected, nil
+}
+
+// runResolvers runs the resolvers of the tasks with at most concurrency of them at the same time, the default
+// number when it's zero or less.
+func runResolvers(ctx context.Context, tasks []resolveTask, concurrency int) []resolveResult {
+	if concurrency <= 0 {
+		concurrency = defaultResolverConcurrency
+	}
+
+	results := make([]resolveResult, len(tasks))
+	next := make(chan int)
+
+	var wg sync.WaitGroup
+
+	for range min(concurrency, len(tasks)) {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range next {
+				if err := ctx.Err(); err != nil {
+					results[i].err = err
+
+					continue
+				}
+
+				results[i].value, results[i].err = tasks[i].r.run(ctx, tasks[i].src)
+			}
+		}()
+	}
+
+	for i := range tasks {
+		next <- i
+	}
+
+	close(next)
+	wg.Wait()
+
+	return results
+}
+
+// run calls the resolver with the source struct, returning as soon as its context is done even if the resolver
+// doesn't check it, in which case the resolver keeps running in its goroutine until it returns.
+func (r resolver) run(ctx context.Context, src reflect.Value) (any, error) {
+	if r.timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+
+	done := make(chan resolveResult, 1)
+
+	go func() {
+		v, err := r.fn(ctx, src)
+		done <- resolveResult{value: v, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.value, res.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// setResolved sets the result of the resolver to its field, with the child selection of the field.
+func setResolved(o options, task resolveTask, value any) error {
+	if value == nil {
+		task.dst.SetZero()
+
+		return nil
+	}
+
+	// values and pointers are converted into each other
+	p := projector{opts: o}
+
+	return p.projectValue(task.node, reflect.ValueOf(value), task.dst)
+}
+
+// collect finds the selected fields with a resolver in src, the source value, and dst, its deep copy.
+//
+//nolint:exhaustive // the rest of kinds don't have fields, maps values can't be set
+func (rc *resolveCollector) collect(node Node, src, dst reflect.Value, path string) {
+	if isLeafType(src.Type()) {
+		return
+	}
+
+	switch src.Kind() {
+	case reflect.Ptr:
+		if src.IsNil() || dst.IsNil() {
+			return
+		}
+
+		if isAllIdentifiers(node) {
+			key := visitKey{ptr: src.Pointer(), typ: src.Type()}
+			if _, ok := rc.visiting[key]; ok {
+				return
+			}
+
+			if rc.visiting == nil {
+				rc.visiting = make(map[visitKey]struct{})
+			}
+
+			rc.visiting[key] = struct{}{}
+			defer delete(rc.visiting, key)
+		}
+
+		rc.collect(node, src.Elem(), dst.Elem(), path)
+	case reflect.Slice, reflect.Array:
+		for i := range min(src.Len(), dst.Len()) {
+			rc.collect(node, src.Index(i), dst.Index(i), joinPath(path, strconv.Itoa(i)))
+		}
+	case reflect.Struct:
+		rc.collectStruct(node, src, dst, path)
+	}
+}
+
+func (rc *resolveCollector) collectStruct(node Node, src, dst reflect.Value, path string) {
+	t := src.Type()
+
+	resolvers.RLock()
+	byField := resolvers.byType[t]
+	resolvers.RUnlock()
+
+	for _, fp := range rc.opts.planFor(t).fields {
+		if fp.ignored {
+			continue
+		}
+
+		child, ok := rc.opts.selectedField(node, t, fp)
+		if !ok {
+			continue
+		}
+
+		fieldPath := joinPath(path, fp.name)
+
+		if r, found := byField[fieldKey(fp.index)]; found {
+			rc.tasks = append(rc.tasks, resolveTask{
+				path: fieldPath,
+				node: child,
+				r:    r,
+				src:  src,
+				dst:  settableFieldByIndex(dst, fp.index),
+			})
+
+			continue
+		}
+
+		sf, srcOK := fieldByIndex(src, fp.index)
+		df, dstOK := fieldByIndex(dst, fp.index)
+
+		if srcOK && dstOK {
+			rc.collect(child, sf, df, fieldPath)
+		}
+	}
+}
+
+func fieldKey(index []int) string {
+	return fmt.Sprint(index)
+}
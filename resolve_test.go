@@ -0,0 +1,201 @@
+package gofieldselect
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type (
+	author struct {
+		ID       int       `json:"id"`
+		Name     string    `json:"name"`
+		Posts    int       `json:"posts"`
+		Avatar   string    `json:"avatar"`
+		Slow     string    `json:"slow"`
+		Failing  string    `json:"failing"`
+		Friends  []author  `json:"friends"`
+		Location *location `json:"location"`
+	}
+
+	location struct {
+		City    string `json:"city"`
+		Country string `json:"country"`
+	}
+
+	// tally counts the calls of its resolver in the *atomic.Int32 of the context, so each test has its own count.
+	tally struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+
+	// gauge records in the *gaugeStats of the context how many of its resolvers run at the same time.
+	gauge struct {
+		Active int `json:"active"`
+	}
+
+	gauges struct {
+		Items []gauge `json:"items"`
+	}
+
+	gaugeStats struct {
+		active atomic.Int32
+		max    atomic.Int32
+	}
+
+	tallyKey struct{}
+	gaugeKey struct{}
+)
+
+//nolint:gochecknoinits // the resolvers are registered once for every test
+func init() {
+	RegisterResolver("posts", func(_ context.Context, a author) (any, error) {
+		return a.ID * 10, nil
+	})
+	RegisterResolver("avatar", func(_ context.Context, a author) (any, error) {
+		return "https://cdn/" + a.Name, nil
+	})
+	RegisterResolver("slow", func(ctx context.Context, _ author) (any, error) {
+		<-ctx.Done()
+
+		return nil, ctx.Err()
+	}, WithResolverTimeout(10*time.Millisecond))
+	RegisterResolver("failing", func(_ context.Context, _ author) (any, error) {
+		return nil, errors.New("boom")
+	})
+	RegisterResolver("location", func(_ context.Context, _ author) (any, error) {
+		return &location{City: "Madrid", Country: "Spain"}, nil
+	})
+	RegisterResolver("active", func(ctx context.Context, _ gauge) (any, error) {
+		//nolint:errcheck // always set by the test
+		stats := ctx.Value(gaugeKey{}).(*gaugeStats)
+
+		active := stats.active.Add(1)
+		defer stats.active.Add(-1)
+
+		for m := stats.max.Load(); active > m && !stats.max.CompareAndSwap(m, active); m = stats.max.Load() {
+		}
+
+		time.Sleep(time.Millisecond)
+
+		return int(active), nil
+	})
+	RegisterResolver("count", func(ctx context.Context, _ tally) (any, error) {
+		//nolint:errcheck // always set by the test
+		ctx.Value(tallyKey{}).(*atomic.Int32).Add(1)
+
+		return 1, nil
+	})
+}
+
+func newAuthor() author {
+	return author{ID: 1, Name: "john", Friends: []author{{ID: 2, Name: "jane"}}}
+}
+
+func TestResolve(t *testing.T) {
+	t.Parallel()
+
+	src := newAuthor()
+
+	got, err := Resolve(t.Context(), parse(t, "name,avatar,friends(posts),location(city)"), src)
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	if got.Avatar != "https://cdn/john" || got.Posts != 0 {
+		t.Fatalf("expected only the avatar resolved, got %+v", got)
+	}
+
+	if got.Friends[0].Posts != 20 || got.Friends[0].Name != "" {
+		t.Fatalf("expected the friend posts resolved, got %+v", got.Friends[0])
+	}
+
+	if got.Location.City != "Madrid" || got.Location.Country != "" {
+		t.Fatalf("expected the resolved location with its selection, got %+v", got.Location)
+	}
+
+	if src.Friends[0].Posts != 0 {
+		t.Fatalf("expected the source unchanged, got %+v", src.Friends[0])
+	}
+}
+
+func TestResolveNotSelected(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	ctx := context.WithValue(t.Context(), tallyKey{}, &calls)
+
+	if _, err := Resolve(ctx, parse(t, "name"), tally{Name: "john"}); err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	if calls.Load() != 0 {
+		t.Fatalf("expected the count resolver not to be called")
+	}
+
+	got, err := Resolve(ctx, parse(t, "count"), tally{Name: "john"})
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	if got.Count != 1 || calls.Load() != 1 {
+		t.Fatalf("expected the count resolver called once, got %+v", got)
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := Resolve(t.Context(), parse(t, "slow,failing,friends(failing)"), newAuthor())
+
+	var re ResolveError
+	if !errors.As(err, &re) || len(re.Unwrap()) != 3 {
+		t.Fatalf("expected 3 resolve errors, got %v", err)
+	}
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the timeout error, got %v", err)
+	}
+
+	var fe FieldError
+	if !errors.As(re.Unwrap()[2], &fe) || fe.Path() != "friends.0.failing" {
+		t.Fatalf("expected the path of the friend field, got %v", re.Unwrap()[2])
+	}
+}
+
+func TestResolveCanceledContext(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	if _, err := Resolve(ctx, parse(t, "posts"), newAuthor()); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestResolveConcurrency(t *testing.T) {
+	t.Parallel()
+
+	var stats gaugeStats
+
+	ctx := context.WithValue(t.Context(), gaugeKey{}, &stats)
+
+	got, err := Resolve(ctx, parse(t, "items"), gauges{Items: make([]gauge, 20)}, WithResolverConcurrency(3))
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	if m := stats.max.Load(); m < 1 || m > 3 {
+		t.Fatalf("expected at most 3 resolvers at the same time, got %d", m)
+	}
+
+	for i, g := range got.Items {
+		if g.Active < 1 {
+			t.Fatalf("expected the item %d resolved, got %+v", i, g)
+		}
+	}
+}
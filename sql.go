@@ -0,0 +1,195 @@
+package gofieldselect
+
+import (
+	"database/sql"
+	"reflect"
+)
+
+// columnTagKey is the struct tag with the column names, see [SQLColumns].
+const columnTagKey = "db"
+
+var _ Rows = new(sql.Rows)
+
+type (
+	// Rows is the part of [sql.Rows] used by [ScanSelected].
+	Rows interface {
+		Columns() ([]string, error)
+		Next() bool
+		Scan(dest ...any) error
+		Err() error
+	}
+
+	// sqlColumn is a column of a struct and the index of its field.
+	sqlColumn struct {
+		name  string
+		index []int
+	}
+)
+
+// WithColumnNaming uses the naming strategy to get the column name of the fields without a name in their `db` tag,
+// [NamingSnakeCase] by default, see [SQLColumns].
+func WithColumnNaming(naming Naming) Option {
+	return func(o *options) {
+		o.columnNaming = naming
+		o.hasColumnNaming = true
+	}
+}
+
+// SQLColumns returns the columns of the fields of the struct [T] selected in [n], in declaration order, to push
+// the projection down to the database, e.g. `SELECT id, name FROM users`.
+// The column names are the ones in the `db` tag, or the field names following [WithColumnNaming], and the fields
+// with the `fieldselect:"pk"` tag are always included.
+// The selected fields without a column, ignored with `db:"-"` or with children like structs and slices, are
+// reported in a [ValidationError], they are skipped when selected through a wildcard.
+func SQLColumns[T any](n Node, opts ...Option) ([]string, error) {
+	columns, err := sqlColumns(n, reflect.TypeFor[T](), newOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.name
+	}
+
+	return names, nil
+}
+
+// ScanSelected scans all the [rows] into new values of the struct [T], setting the field of each column.
+// The columns must be the ones returned by [SQLColumns] for [n], in any order, so only the selected fields are set.
+func ScanSelected[T any](rows Rows, n Node, opts ...Option) ([]T, error) {
+	t := reflect.TypeFor[T]()
+
+	columns, err := sqlColumns(n, t, newOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string][]int, len(columns))
+	for _, c := range columns {
+		byName[c.name] = c.index
+	}
+
+	names, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	indexes := make([][]int, len(names))
+
+	for i, name := range names {
+		index, ok := byName[name]
+		if !ok {
+			return nil, NewFieldError(name, ErrUnknownField)
+		}
+
+		indexes[i] = index
+	}
+
+	var (
+		values []T
+		dest   = make([]any, len(names))
+	)
+
+	for rows.Next() {
+		var v T
+
+		rv := reflect.ValueOf(&v).Elem()
+		for i, index := range indexes {
+			dest[i] = settableFieldByIndex(rv, index).Addr().Interface()
+		}
+
+		if err = rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+
+		values = append(values, v)
+	}
+
+	return values, rows.Err()
+}
+
+// sqlColumns returns the columns of the fields of t selected in node, primary keys included.
+func sqlColumns(node Node, t reflect.Type, o options) ([]sqlColumn, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, NewTypeNotValidError(t.Kind())
+	}
+
+	naming := NamingSnakeCase
+	if o.hasColumnNaming {
+		naming = o.columnNaming
+	}
+
+	columnPlan := planForKey(planKey{typ: t, tagKey: columnTagKey, naming: naming})
+
+	byField := make(map[string]fieldPlan, len(columnPlan.fields))
+	for _, fp := range columnPlan.fields {
+		if !fp.ignored {
+			byField[fieldKey(fp.index)] = fp
+		}
+	}
+
+	tp := o.planFor(t)
+
+	var errs []error
+
+	if identifiers, ok := node.(Identifiers); ok {
+		for _, ident := range identifiers {
+			fp, found := o.field(tp, ident.Value)
+			if !found || fp.tag.mode == modeNever {
+				errs = append(errs, NewFieldError(ident.Value, ErrUnknownField))
+
+				continue
+			}
+
+			if _, mapped := byField[fieldKey(fp.index)]; !mapped || !isColumnType(fp.typ) {
+				errs = append(errs, NewFieldError(ident.Value, ErrUnmappedField))
+
+				continue
+			}
+
+			if !isAllIdentifiers(childNode(ident)) {
+				errs = append(errs, NewFieldError(ident.Value, ErrChildSelectionOnLeaf))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, NewValidationError(errs)
+	}
+
+	var columns []sqlColumn
+
+	for _, fp := range tp.fields {
+		column, mapped := byField[fieldKey(fp.index)]
+		if !mapped || fp.ignored || !isColumnType(fp.typ) {
+			continue
+		}
+
+		if _, selected := o.selectedField(node, t, fp); selected || fp.tag.pk {
+			columns = append(columns, sqlColumn{name: column.name, index: fp.index})
+		}
+	}
+
+	return columns, nil
+}
+
+// isColumnType reports whether the values of t can be scanned from a single column.
+//
+//nolint:exhaustive // the rest of kinds are single values
+func isColumnType(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if isLeafType(t) || isBytes(t) || reflect.PointerTo(t).Implements(reflect.TypeFor[sql.Scanner]()) {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.Struct, reflect.Slice, reflect.Array, reflect.Map, reflect.Interface, reflect.Chan, reflect.Func:
+		return false
+	default:
+		return true
+	}
+}
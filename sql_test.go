@@ -0,0 +1,119 @@
+package gofieldselect
+
+import (
+	"database/sql"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+type (
+	product struct {
+		ID        int            `json:"id"        db:"product_id" fieldselect:"pk"`
+		Name      string         `json:"name"`
+		UnitPrice float64        `json:"unitPrice"`
+		Notes     sql.NullString `json:"notes"`
+		CreatedAt *time.Time     `json:"createdAt"`
+		Internal  string         `json:"internal"  db:"-"`
+		Tags      []string       `json:"tags"`
+	}
+
+	// fakeRows returns the rows of values, one column each value.
+	fakeRows struct {
+		columns []string
+		rows    [][]any
+		current int
+	}
+)
+
+func (r *fakeRows) Columns() ([]string, error) { return r.columns, nil }
+
+func (r *fakeRows) Next() bool {
+	r.current++
+
+	return r.current <= len(r.rows)
+}
+
+func (r *fakeRows) Scan(dest ...any) error {
+	for i, d := range dest {
+		switch d := d.(type) {
+		case *int:
+			*d, _ = r.rows[r.current-1][i].(int)
+		case *string:
+			*d, _ = r.rows[r.current-1][i].(string)
+		case *float64:
+			*d, _ = r.rows[r.current-1][i].(float64)
+		default:
+			return errors.New("unexpected destination")
+		}
+	}
+
+	return nil
+}
+
+func (r *fakeRows) Err() error { return nil }
+
+func TestSQLColumns(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string][]string{
+		"name,unitPrice":  {"product_id", "name", "unit_price"},
+		"notes,createdAt": {"product_id", "notes", "created_at"},
+		"":                {"product_id", "name", "unit_price", "notes", "created_at"},
+	}
+
+	for sel, expected := range tests {
+		got, err := SQLColumns[product](parse(t, sel))
+		if err != nil {
+			t.Fatalf("%q: error: %v", sel, err)
+		}
+
+		if !slices.Equal(got, expected) {
+			t.Fatalf("%q: expected %v, got %v", sel, expected, got)
+		}
+	}
+
+	got, err := SQLColumns[product](parse(t, "unitPrice"), WithColumnNaming(NamingCamelCase))
+	if err != nil || !slices.Equal(got, []string{"product_id", "unitPrice"}) {
+		t.Fatalf("expected camelCase columns, got %v, %v", got, err)
+	}
+}
+
+func TestSQLColumnsUnmapped(t *testing.T) {
+	t.Parallel()
+
+	_, err := SQLColumns[product](parse(t, "internal,tags,unknown,name(first)"))
+
+	var ve ValidationError
+	if !errors.As(err, &ve) || len(ve.Unwrap()) != 4 {
+		t.Fatalf("expected 4 errors, got %v", err)
+	}
+
+	if !errors.Is(err, ErrUnmappedField) || !errors.Is(err, ErrUnknownField) || !errors.Is(err, ErrChildSelectionOnLeaf) {
+		t.Fatalf("expected unmapped, unknown and child selection errors, got %v", err)
+	}
+}
+
+func TestScanSelected(t *testing.T) {
+	t.Parallel()
+
+	rows := &fakeRows{
+		columns: []string{"name", "product_id"},
+		rows:    [][]any{{"pen", 1}, {"book", 2}},
+	}
+
+	got, err := ScanSelected[product](rows, parse(t, "name"))
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	if len(got) != 2 || got[0].ID != 1 || got[0].Name != "pen" || got[1].ID != 2 || got[1].Name != "book" {
+		t.Fatalf("expected the scanned products, got %+v", got)
+	}
+
+	rows = &fakeRows{columns: []string{"unit_price"}}
+	if _, err = ScanSelected[product](rows, parse(t, "name")); !errors.Is(err, ErrUnknownField) {
+		t.Fatalf("expected ErrUnknownField for a column not selected, got %v", err)
+	}
+}
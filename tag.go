@@ -0,0 +1,174 @@
+package gofieldselect
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// tagKey is the struct tag used to configure how a field is selected.
+const tagKey = "fieldselect"
+
+//nolint:gochecknoglobals // cache of the types reaching restricted fields, shared by every call
+var restrictedTypes sync.Map // map[reflect.Type]bool
+
+// fieldMode decides when a field is selected, set with a keyword in the `fieldselect` tag.
+type fieldMode int
+
+const (
+	// modeDefault selects the field when named or through a wildcard.
+	modeDefault fieldMode = iota
+	// modeAlways selects the field, with all its children, even if it isn't named.
+	modeAlways
+	// modeExplicit only selects the field when named, never through a wildcard.
+	modeExplicit
+	// modeNever never selects the field, like if it didn't exist.
+	modeNever
+)
+
+// tagOptions holds the options of the `fieldselect` struct tag, separated by semicolons,
+// e.g. `fieldselect:"from=surname"` or `fieldselect:"always"`.
+type tagOptions struct {
+	// name is the selection name when the tag key is `fieldselect`, see [WithTagKey].
+	name string
+	// from is the selection name of the field in the source struct when projecting between types, see [Project].
+	from string
+	mode fieldMode
+	// roles are the roles allowed to see the field, every role when empty, see [ProjectFor].
+	roles []string
+	// requires is the selection the field depends on, e.g. `name,surname` for `fullName`,
+	// see [ExpandDependencies].
+	requires Node
+	// requiresErr is the error parsing an invalid requires, reported by [ExpandDependencies] and [Validate]
+	// when the field is selected.
+	requiresErr error
+	// pk is true for the primary keys, always included in the columns, see [SQLColumns].
+	pk bool
+}
+
+func parseTagOptions(sf reflect.StructField) tagOptions {
+	var opts tagOptions
+
+	for option := range strings.SplitSeq(sf.Tag.Get(tagKey), ";") {
+		key, value, _ := strings.Cut(strings.TrimSpace(option), "=")
+
+		switch strings.TrimSpace(key) {
+		case "name":
+			opts.name = strings.TrimSpace(value)
+		case "from":
+			opts.from = strings.TrimSpace(value)
+		case "roles":
+			for role := range strings.SplitSeq(value, ",") {
+				if role = strings.TrimSpace(role); role != "" {
+					opts.roles = append(opts.roles, role)
+				}
+			}
+		case "requires":
+			// a selection, an invalid one is kept to be reported when the field is selected
+			if value = strings.TrimSpace(value); value != "" {
+				n, err := Parse(value)
+				if err != nil {
+					opts.requiresErr = fmt.Errorf("%w %q: %w", ErrInvalidRequires, value, err)
+				} else {
+					opts.requires = n
+				}
+			}
+		case "pk":
+			opts.pk = true
+		case "always":
+			opts.mode = modeAlways
+		case "explicit":
+			opts.mode = modeExplicit
+		case "never":
+			opts.mode = modeNever
+		default:
+			// unknown options are ignored
+		}
+	}
+
+	return opts
+}
+
+// restricted reports whether the field can't be selected through a wildcard.
+func (o tagOptions) restricted() bool {
+	return o.mode == modeExplicit || o.mode == modeNever
+}
+
+// selectedField returns the selection of the field of the struct type t in node, honoring the mode of its
+// `fieldselect` tag: `always` fields are selected whole when not named, `explicit` ones only when named and `never`
+// ones are never selected. The fields the caller isn't allowed to see are never selected either.
+func (o options) selectedField(node Node, t reflect.Type, fp fieldPlan) (Node, bool) {
+	if !o.allowed(t, fp) {
+		return nil, false
+	}
+
+	switch fp.tag.mode {
+	case modeNever:
+		return nil, false
+	case modeExplicit:
+		if isAllIdentifiers(node) {
+			return nil, false
+		}
+	default:
+	}
+
+	ident, ok := o.selectField(node, fp.name)
+	if !ok {
+		if fp.tag.mode == modeAlways {
+			return AllIdentifiers{}, true
+		}
+
+		return nil, false
+	}
+
+	return childNode(ident), true
+}
+
+// restricted reports whether some fields reachable from t may not be selected through a wildcard, because of
+// their mode or the roles of the caller, so its values can't be taken as a whole.
+func (o options) restricted(t reflect.Type) bool {
+	return o.access != nil || hasRestrictedFields(t)
+}
+
+// hasRestrictedFields reports whether a wildcard selection of t reaches an `explicit` or `never` field,
+// so its values can't be copied as a whole.
+func hasRestrictedFields(t reflect.Type) bool {
+	if r, ok := restrictedTypes.Load(t); ok {
+		//nolint:errcheck // it's always a bool
+		return r.(bool)
+	}
+
+	r := findRestrictedFields(t, make(map[reflect.Type]struct{}))
+	restrictedTypes.Store(t, r)
+
+	return r
+}
+
+//nolint:exhaustive // the rest of kinds don't have fields
+func findRestrictedFields(t reflect.Type, visiting map[reflect.Type]struct{}) bool {
+	if isLeafType(t) {
+		return false
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return findRestrictedFields(t.Elem(), visiting)
+	case reflect.Struct:
+		if _, ok := visiting[t]; ok {
+			return false
+		}
+
+		visiting[t] = struct{}{}
+
+		for _, fp := range planFor(t).fields {
+			if fp.tag.restricted() || findRestrictedFields(fp.typ, visiting) {
+				return true
+			}
+		}
+
+		return false
+	default:
+		return false
+	}
+}
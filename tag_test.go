@@ -0,0 +1,241 @@
+package gofieldselect
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type account struct {
+	ID       int       `json:"id"       fieldselect:"always"`
+	Email    string    `json:"email"    fieldselect:"explicit"`
+	Password string    `json:"password" fieldselect:"never"`
+	Token    string    `json:"-"        fieldselect:"never"`
+	Name     string    `json:"name"`
+	Owner    *account  `json:"owner"`
+	Members  []account `json:"members"`
+}
+
+func newAccount() account {
+	return account{
+		ID:       1,
+		Email:    "john@doe.com",
+		Password: "secret",
+		Token:    "token",
+		Name:     "John",
+		Owner:    &account{ID: 2, Email: "jane@doe.com", Password: "secret", Name: "Jane"},
+		Members:  []account{{ID: 3, Email: "jim@doe.com", Password: "secret"}},
+	}
+}
+
+func TestGetWithReflectionFieldModesWildcard(t *testing.T) {
+	t.Parallel()
+
+	got, err := GetWithReflection(parse(t, ""), newAccount())
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	if got.ID != 1 || got.Name != "John" {
+		t.Fatalf("expected id and name, got %+v", got)
+	}
+
+	if got.Email != "" || got.Password != "" || got.Token != "" {
+		t.Fatalf("expected no explicit or never fields, got %+v", got)
+	}
+
+	if got.Owner.Name != "Jane" || got.Owner.Email != "" || got.Owner.Password != "" {
+		t.Fatalf("expected the owner without restricted fields, got %+v", got.Owner)
+	}
+
+	if got.Members[0].ID != 3 || got.Members[0].Email != "" || got.Members[0].Password != "" {
+		t.Fatalf("expected the members without restricted fields, got %+v", got.Members)
+	}
+}
+
+func TestGetWithReflectionFieldModesNamed(t *testing.T) {
+	t.Parallel()
+
+	src := newAccount()
+
+	got, err := GetWithReflection(parse(t, "email,password,owner(name)"), src)
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	if got.ID != 1 || got.Email != "john@doe.com" || got.Password != "" || got.Name != "" {
+		t.Fatalf("expected always id and explicit email, got %+v", got)
+	}
+
+	if got.Owner.ID != 2 || got.Owner.Name != "Jane" || got.Owner.Email != "" {
+		t.Fatalf("expected the owner id and name, got %+v", got.Owner)
+	}
+
+	if src.Password != "secret" || src.Owner.Email != "jane@doe.com" {
+		t.Fatalf("expected the source unchanged, got %+v", src)
+	}
+}
+
+func TestCompileFieldModes(t *testing.T) {
+	t.Parallel()
+
+	for _, sel := range []string{"", "email,owner,members(name)", "name,owner(email)"} {
+		n := parse(t, sel)
+
+		expected, err := GetWithReflection(n, newAccount())
+		if err != nil {
+			t.Fatalf("error: %v", err)
+		}
+
+		project, err := Compile[account](n)
+		if err != nil {
+			t.Fatalf("error: %v", err)
+		}
+
+		got := project(newAccount())
+		if got.Email != expected.Email || got.Password != "" || got.Owner.Email != expected.Owner.Email ||
+			len(got.Members) != len(expected.Members) {
+			t.Fatalf("%q: expected %+v, got %+v", sel, expected, got)
+		}
+	}
+}
+
+func TestValidateFieldModes(t *testing.T) {
+	t.Parallel()
+
+	if err := Validate[account](parse(t, "id,email,owner(email)")); err != nil {
+		t.Fatalf("expected a valid selection, got %v", err)
+	}
+
+	err := Validate[account](parse(t, "password"))
+	if !errors.Is(err, ErrUnknownField) {
+		t.Fatalf("expected ErrUnknownField, got %v", err)
+	}
+}
+
+func TestEncodersFieldModesWildcard(t *testing.T) {
+	t.Parallel()
+
+	encoders := map[string]func(n Node) ([]byte, error){
+		"Marshal":    func(n Node) ([]byte, error) { return Marshal(n, newAccount()) },
+		"MarshalXML": func(n Node) ([]byte, error) { return MarshalXML(n, newAccount()) },
+		"ToMap": func(n Node) ([]byte, error) {
+			m, err := ToMap(n, newAccount())
+			if err != nil {
+				return nil, err
+			}
+
+			return json.Marshal(m)
+		},
+		"Apply": func(n Node) ([]byte, error) {
+			a := newAccount()
+			if err := Apply(n, &a); err != nil {
+				return nil, err
+			}
+
+			return json.Marshal(struct {
+				account
+
+				Token string
+			}{a, a.Token})
+		},
+		"Project": func(n Node) ([]byte, error) {
+			a, err := Project[account, account](n, newAccount())
+			if err != nil {
+				return nil, err
+			}
+
+			return json.Marshal(a)
+		},
+	}
+
+	for name, encode := range encoders {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// the explicit email and the name of the owner, the XML names are the field names
+			named := "email,owner(name)"
+			if name == "MarshalXML" {
+				named = "Email,Owner(Name)"
+			}
+
+			got, err := encode(AllIdentifiers{})
+			if err != nil {
+				t.Fatalf("error: %v", err)
+			}
+
+			if !bytes.Contains(got, []byte("John")) || !bytes.Contains(got, []byte("Jane")) {
+				t.Fatalf("expected the selectable fields, got %s", got)
+			}
+
+			if bytes.Contains(got, []byte("secret")) || bytes.Contains(got, []byte("token")) ||
+				bytes.Contains(got, []byte("@doe.com")) {
+				t.Fatalf("expected no explicit or never fields, got %s", got)
+			}
+
+			got, err = encode(parse(t, named))
+			if err != nil {
+				t.Fatalf("error: %v", err)
+			}
+
+			if !bytes.Contains(got, []byte("john@doe.com")) || bytes.Contains(got, []byte("jane@doe.com")) ||
+				bytes.Contains(got, []byte("secret")) {
+				t.Fatalf("expected only the named explicit field, got %s", got)
+			}
+		})
+	}
+}
+
+func TestWriteCSVFieldModes(t *testing.T) {
+	t.Parallel()
+
+	type row struct {
+		ID       int    `json:"id"       fieldselect:"always"`
+		Email    string `json:"email"    fieldselect:"explicit"`
+		Password string `json:"password" fieldselect:"never"`
+		Name     string `json:"name"`
+	}
+
+	rows := []row{{ID: 1, Email: "john@doe.com", Password: "secret", Name: "John"}}
+
+	tests := map[string]string{
+		"":           "id,name\n1,John\n",
+		"name,email": "name,email,id\nJohn,john@doe.com,1\n",
+	}
+
+	for sel, expected := range tests {
+		t.Run(sel, func(t *testing.T) {
+			t.Parallel()
+
+			var sb strings.Builder
+			if err := WriteCSV(&sb, parse(t, sel), rows); err != nil {
+				t.Fatalf("error: %v", err)
+			}
+
+			if sb.String() != expected {
+				t.Fatalf("expected %q, got %q", expected, sb.String())
+			}
+		})
+	}
+
+	if err := WriteCSV(&strings.Builder{}, parse(t, "password"), rows); !errors.Is(err, ErrUnknownField) {
+		t.Fatalf("expected ErrUnknownField, got %v", err)
+	}
+}
+
+func TestApplyFieldModesCycle(t *testing.T) {
+	t.Parallel()
+
+	a := newAccount()
+	a.Owner = &a
+
+	if err := Apply(AllIdentifiers{}, &a); err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	if a.Password != "" || a.Email != "" || a.Name != "John" || a.Owner != &a {
+		t.Fatalf("expected the cycle kept without restricted fields, got %+v", a)
+	}
+}
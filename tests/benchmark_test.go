@@ -12,6 +12,18 @@ type user struct {
 	Age     int    `json:"age"`
 }
 
+type address struct {
+	Street string `json:"street"`
+	Number int    `json:"number"`
+}
+
+type nestedUser struct {
+	Name    string   `json:"name"`
+	Surname string   `json:"surname"`
+	Age     int      `json:"age"`
+	Address *address `json:"address"`
+}
+
 type user2 struct {
 	Name    *string `json:"name,omitempty"`
 	Surname *string `json:"surname,omitempty"`
@@ -22,6 +34,7 @@ type user2 struct {
 var (
 	other  user
 	other2 user2
+	other3 nestedUser
 )
 
 func BenchmarkGetWithReflection(b *testing.B) {
@@ -36,6 +49,9 @@ func BenchmarkGetWithReflection(b *testing.B) {
 		b.Fatal(err)
 	}
 
+	b.ReportAllocs()
+	b.ResetTimer()
+
 	for range b.N {
 		other, _ = gofieldselect.GetWithReflection(fields, u)
 	}
@@ -53,6 +69,9 @@ func BenchmarkGet(b *testing.B) {
 		b.Fatal(err)
 	}
 
+	b.ReportAllocs()
+	b.ResetTimer()
+
 	for range b.N {
 		other2 = user2{
 			Name:    gofieldselect.Get(fields, "name", &u.Name),
@@ -61,3 +80,50 @@ func BenchmarkGet(b *testing.B) {
 		}
 	}
 }
+
+func BenchmarkGetWithReflectionNested(b *testing.B) {
+	u := nestedUser{
+		Name:    "John",
+		Surname: "Doe",
+		Age:     20,
+		Address: &address{Street: "Main", Number: 42},
+	}
+
+	fields, err := gofieldselect.Parse("name,address(street)")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for range b.N {
+		other3, _ = gofieldselect.GetWithReflection(fields, u)
+	}
+}
+
+func BenchmarkCompile(b *testing.B) {
+	u := nestedUser{
+		Name:    "John",
+		Surname: "Doe",
+		Age:     20,
+		Address: &address{Street: "Main", Number: 42},
+	}
+
+	fields, err := gofieldselect.Parse("name,address(street)")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	project, err := gofieldselect.Compile[nestedUser](fields)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for range b.N {
+		other3 = project(u)
+	}
+}
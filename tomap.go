@@ -0,0 +1,178 @@
+package gofieldselect
+
+import (
+	"reflect"
+)
+
+// mapper converts values to their generic representation, maps, slices and leaf values, applying a selection.
+type mapper struct {
+	opts options
+	// visiting holds the pointers being converted with a wildcard selection, to detect cycles.
+	visiting map[visitKey]struct{}
+}
+
+// ToMap returns a map with only the keys specified in [n], so a selected field with its zero value is still present,
+// unlike the not selected ones.
+// [v] must be a struct, a map with string keys or a pointer to them. Nested structs and maps are converted to
+// map[string]any and slices and arrays to []any, while leaf values, see [RegisterLeafType], are kept as they are.
+// A nil [v] returns a nil map. The keys are the selection names, see [WithTagKey] and [WithNaming].
+//
+//nolint:nilnil // a nil value is represented by a nil map
+func ToMap(n Node, v any, opts ...Option) (map[string]any, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, nil
+		}
+
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct && (rv.Kind() != reflect.Map || rv.Type().Key().Kind() != reflect.String) {
+		return nil, NewTypeNotValidError(rv.Kind())
+	}
+
+	m := mapper{opts: newOptions(opts)}
+
+	res, err := m.toValue(n, rv)
+	if err != nil {
+		return nil, err
+	}
+
+	//nolint:errcheck // structs and maps are always converted to map[string]any
+	return res.(map[string]any), nil
+}
+
+// toValue returns the generic representation of v with only the fields in node.
+//
+//nolint:exhaustive,nilnil // the rest of kinds are returned as they are, nil represents nil values
+func (m *mapper) toValue(node Node, v reflect.Value) (any, error) {
+	if isLeafType(v.Type()) {
+		if !isAllIdentifiers(node) {
+			return nil, ErrChildSelectionOnLeaf
+		}
+
+		return v.Interface(), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return m.structToMap(node, v)
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil, nil
+		}
+
+		if !isAllIdentifiers(node) {
+			return m.toValue(node, v.Elem())
+		}
+
+		key := visitKey{ptr: v.Pointer(), typ: v.Type()}
+		if _, ok := m.visiting[key]; ok {
+			return nil, ErrCyclicValue
+		}
+
+		if m.visiting == nil {
+			m.visiting = make(map[visitKey]struct{})
+		}
+
+		m.visiting[key] = struct{}{}
+		defer delete(m.visiting, key)
+
+		return m.toValue(node, v.Elem())
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil, nil
+		}
+
+		return m.toValue(node, v.Elem())
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil, nil
+		}
+
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			// bytes are a single value, like encoding/json does
+			return v.Interface(), nil
+		}
+
+		s := make([]any, v.Len())
+		for i := range v.Len() {
+			elem, err := m.toValue(node, v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+
+			s[i] = elem
+		}
+
+		return s, nil
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return v.Interface(), nil
+		}
+
+		return m.mapToMap(node, v)
+	default:
+		return v.Interface(), nil
+	}
+}
+
+func (m *mapper) structToMap(node Node, v reflect.Value) (map[string]any, error) {
+	res := make(map[string]any)
+
+	t := v.Type()
+
+	for _, fp := range m.opts.planFor(t).fields {
+		if fp.ignored {
+			continue
+		}
+
+		child, ok := m.opts.selectedField(node, t, fp)
+		if !ok {
+			continue
+		}
+
+		sf, exists := fieldByIndex(v, fp.index)
+		if !exists {
+			continue
+		}
+
+		fv, err := m.toValue(child, sf)
+		if err != nil {
+			return nil, wrapFieldError(fp.name, err)
+		}
+
+		res[fp.name] = fv
+	}
+
+	return res, nil
+}
+
+//nolint:nilnil // a nil map is kept nil
+func (m *mapper) mapToMap(node Node, v reflect.Value) (map[string]any, error) {
+	if v.IsNil() {
+		return nil, nil
+	}
+
+	res := make(map[string]any, v.Len())
+
+	iter := v.MapRange()
+	for iter.Next() {
+		key := iter.Key().String()
+
+		ident, ok := m.opts.selectField(node, key)
+		if !ok {
+			continue
+		}
+
+		mv, err := m.toValue(childNode(ident), iter.Value())
+		if err != nil {
+			return nil, wrapFieldError(key, err)
+		}
+
+		res[key] = mv
+	}
+
+	return res, nil
+}
@@ -0,0 +1,100 @@
+package gofieldselect
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestToMap(t *testing.T) {
+	t.Parallel()
+
+	createdAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type Profile struct {
+		Name      string            `json:"name"`
+		Age       int               `json:"age"`
+		Password  string            `json:"-"`
+		CreatedAt time.Time         `json:"createdAt"`
+		Address   *Address          `json:"address"`
+		Previous  []Address         `json:"previous"`
+		Labels    map[string]string `json:"labels"`
+	}
+
+	src := Profile{
+		Name:      "John",
+		Password:  "secret",
+		CreatedAt: createdAt,
+		Address:   &Address{Street: "Main", Number: 1},
+		Previous:  []Address{{Street: "Old", Number: 2}},
+		Labels:    map[string]string{"env": "prod", "tier": "1"},
+	}
+
+	tests := map[string]struct {
+		selection string
+		expected  map[string]any
+	}{
+		"selected zero value": {
+			selection: "name,age",
+			expected:  map[string]any{"name": "John", "age": 0},
+		},
+		"nested": {
+			selection: "address(street),previous(number),labels(env)",
+			expected: map[string]any{
+				"address":  map[string]any{"street": "Main"},
+				"previous": []any{map[string]any{"number": 2}},
+				"labels":   map[string]any{"env": "prod"},
+			},
+		},
+		"all fields": {
+			selection: "",
+			expected: map[string]any{
+				"name":      "John",
+				"age":       0,
+				"createdAt": createdAt,
+				"address":   map[string]any{"street": "Main", "number": 1},
+				"previous":  []any{map[string]any{"street": "Old", "number": 2}},
+				"labels":    map[string]any{"env": "prod", "tier": "1"},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ToMap(parse(t, tc.selection), &src)
+			if err != nil {
+				t.Fatalf("error: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Fatalf("expected %+v; got %+v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestToMapErrors(t *testing.T) {
+	t.Parallel()
+
+	type Link struct {
+		Next *Link `json:"next"`
+	}
+
+	cyclic := &Link{}
+	cyclic.Next = cyclic
+
+	if _, err := ToMap(parse(t, ""), cyclic); !errors.Is(err, ErrCyclicValue) {
+		t.Fatalf("expected ErrCyclicValue; got %v", err)
+	}
+
+	if _, err := ToMap(parse(t, "createdAt(foo)"), Event{}); !errors.Is(err, ErrChildSelectionOnLeaf) {
+		t.Fatalf("expected ErrChildSelectionOnLeaf; got %v", err)
+	}
+
+	if _, err := ToMap(parse(t, "name"), 1); !errors.As(err, &TypeNotValidError{}) {
+		t.Fatalf("expected TypeNotValidError; got %v", err)
+	}
+}
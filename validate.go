@@ -0,0 +1,82 @@
+package gofieldselect
+
+import (
+	"reflect"
+)
+
+// Validate checks that the selection [n] can be applied to the type [T].
+// It reports the selected fields that don't exist in [T] and the child selections on fields without children,
+// like a child selection on a [time.Time].
+// The fields with the `never` mode in the `fieldselect` tag are reported as unknown, and the selected fields with an
+// invalid `requires` in the `fieldselect` tag with [ErrInvalidRequires].
+// The field names follow the [WithTagKey], [WithNaming] and [WithCaseInsensitive] options.
+func Validate[T any](n Node, opts ...Option) error {
+	errs := validateType(newOptions(opts), n, reflect.TypeFor[T](), "")
+	if len(errs) > 0 {
+		return NewValidationError(errs)
+	}
+
+	return nil
+}
+
+// validateType validates the selection node against the type t, located in path.
+//
+//nolint:exhaustive // the rest of kinds don't have children
+func validateType(o options, n Node, t reflect.Type, path string) []error {
+	t = elemType(t)
+
+	identifiers, ok := n.(Identifiers)
+	if !ok {
+		// every field is selected, always valid
+		return nil
+	}
+
+	if isLeafType(t) {
+		return []error{NewFieldError(path, ErrChildSelectionOnLeaf)}
+	}
+
+	var errs []error
+
+	switch t.Kind() {
+	case reflect.Struct:
+		for _, ident := range identifiers {
+			fieldPath := joinPath(path, ident.Value)
+
+			fp, found := o.field(o.planFor(t), ident.Value)
+			if !found || fp.tag.mode == modeNever {
+				errs = append(errs, NewFieldError(fieldPath, ErrUnknownField))
+
+				continue
+			}
+
+			if fp.tag.requiresErr != nil {
+				errs = append(errs, NewFieldError(fieldPath, fp.tag.requiresErr))
+			}
+
+			errs = append(errs, validateType(o, childNode(ident), fp.typ, fieldPath)...)
+		}
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return []error{NewFieldError(path, ErrChildSelectionOnLeaf)}
+		}
+
+		for _, ident := range identifiers {
+			errs = append(errs, validateType(o, childNode(ident), t.Elem(), joinPath(path, ident.Value))...)
+		}
+	case reflect.Interface:
+		// the actual type is only known at runtime
+	default:
+		return []error{NewFieldError(path, ErrChildSelectionOnLeaf)}
+	}
+
+	return errs
+}
+
+// elemType returns the type a selection applies to, going through pointers, slices and arrays.
+func elemType(t reflect.Type) reflect.Type {
+	for !isLeafType(t) && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+		t = t.Elem()
+	}
+
+	return t
+}
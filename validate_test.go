@@ -0,0 +1,64 @@
+package gofieldselect
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		selection string
+		expected  []error
+	}{
+		"all fields": {
+			selection: "",
+		},
+		"nested fields": {
+			selection: "name,address(street,number)",
+		},
+		"ignored JSON field": {
+			selection: "name,password",
+			expected:  []error{ErrUnknownField},
+		},
+		"unknown nested field": {
+			selection: "address(street,zip),foo",
+			expected:  []error{ErrUnknownField, ErrUnknownField},
+		},
+		"child selection on scalar": {
+			selection: "name(first)",
+			expected:  []error{ErrChildSelectionOnLeaf},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := Validate[*User](parse(t, tc.selection))
+			if len(tc.expected) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				return
+			}
+
+			var ve ValidationError
+			if !errors.As(err, &ve) {
+				t.Fatalf("expected ValidationError; got %v", err)
+			}
+
+			if len(ve.errSlice) != len(tc.expected) {
+				t.Fatalf("expected %d errors; got %v", len(tc.expected), ve.errSlice)
+			}
+
+			for i, e := range tc.expected {
+				if !errors.Is(ve.errSlice[i], e) {
+					t.Fatalf("expected error %d to be %v; got %v", i, e, ve.errSlice[i])
+				}
+			}
+		})
+	}
+}
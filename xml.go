@@ -0,0 +1,544 @@
+package gofieldselect
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+//nolint:gochecknoglobals // cache of the XML struct plans, shared by every call
+var xmlPlans sync.Map // map[reflect.Type]*xmlTypePlan
+
+type (
+	// XMLEncoder writes the XML encoding of values with only the fields of a selection, see [NewXMLEncoder].
+	XMLEncoder struct {
+		w    io.Writer
+		n    Node
+		opts []Option
+	}
+
+	// xmlTypePlan holds the precomputed XML fields of a struct type.
+	xmlTypePlan struct {
+		// name is the element name set by the XMLName field, if any.
+		name   xml.Name
+		fields []xmlFieldPlan
+	}
+
+	// xmlFieldPlan describes an exported field of a struct following the encoding/xml tag semantics.
+	xmlFieldPlan struct {
+		name xml.Name
+		// parents holds the parent elements of `a>b` tags, the selection of `a>b` is `a(b)`.
+		parents   []string
+		index     []int
+		kind      xmlFieldKind
+		omitEmpty bool
+		tag       tagOptions
+	}
+
+	xmlFieldKind int
+
+	// xmlEncoder writes XML applying a selection.
+	xmlEncoder struct {
+		opts options
+		buf  bytes.Buffer
+		enc  *xml.Encoder
+		// visiting holds the pointers being encoded with a wildcard selection, to detect cycles.
+		visiting map[visitKey]struct{}
+	}
+)
+
+const (
+	xmlElement xmlFieldKind = iota
+	xmlAttr
+	xmlCharData
+	xmlInnerXML
+	xmlComment
+)
+
+//nolint:gochecknoglobals // reflect types used to check the XML marshalers
+var (
+	xmlNameType      = reflect.TypeFor[xml.Name]()
+	xmlMarshalerType = reflect.TypeFor[xml.Marshaler]()
+)
+
+// MarshalXML returns the XML encoding of [v] with only the elements and attributes specified in [n].
+// The selection names are resolved from the `xml` struct tags: attributes are selected by their name,
+// `a>b` paths as `a(b)`, and character data, inner XML and comments are written with their parent element.
+// Since the names are always the XML ones, [WithTagKey] and [WithNaming] return [ErrUnsupportedOption].
+func MarshalXML(n Node, v any, opts ...Option) ([]byte, error) {
+	if n == nil {
+		n = AllIdentifiers{}
+	}
+
+	o := newOptions(opts)
+	if o.tagKey != "" || o.naming != NamingFieldName {
+		return nil, ErrUnsupportedOption
+	}
+
+	e := &xmlEncoder{opts: o}
+	e.enc = xml.NewEncoder(&e.buf)
+
+	if err := e.encodeValue(n, reflect.ValueOf(v), xml.StartElement{}); err != nil {
+		return nil, err
+	}
+
+	if err := e.enc.Flush(); err != nil {
+		return nil, err
+	}
+
+	return e.buf.Bytes(), nil
+}
+
+// NewXMLEncoder returns a new encoder that writes to [w] the values with only the elements and attributes
+// specified in [n].
+func NewXMLEncoder(w io.Writer, n Node, opts ...Option) *XMLEncoder {
+	return &XMLEncoder{w: w, n: n, opts: opts}
+}
+
+// Encode writes the selected XML encoding of [v].
+func (enc *XMLEncoder) Encode(v any) error {
+	b, err := MarshalXML(enc.n, v, enc.opts...)
+	if err != nil {
+		return err
+	}
+
+	_, err = enc.w.Write(b)
+
+	return err
+}
+
+// encodeValue writes v as one element, or one element per item for slices, with only the fields in node.
+//
+//nolint:exhaustive // the rest of kinds are encoded by encoding/xml
+func (e *xmlEncoder) encodeValue(node Node, v reflect.Value, start xml.StartElement) error {
+	if !v.IsValid() {
+		return nil
+	}
+
+	if isXMLLeaf(v.Type()) {
+		if !isAllIdentifiers(node) {
+			return ErrChildSelectionOnLeaf
+		}
+
+		return e.enc.EncodeElement(addressable(v), e.startFor(v.Type(), start))
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		return e.encodePtr(node, v, start)
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+
+		return e.encodeValue(node, v.Elem(), start)
+	case reflect.Slice, reflect.Array:
+		if isBytes(v.Type()) {
+			return e.enc.EncodeElement(addressable(v), e.startFor(v.Type(), start))
+		}
+
+		for i := range v.Len() {
+			if err := e.encodeValue(node, v.Index(i), start); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case reflect.Struct:
+		return e.encodeStruct(node, v, start)
+	default:
+		return e.enc.EncodeElement(addressable(v), e.startFor(v.Type(), start))
+	}
+}
+
+func (e *xmlEncoder) encodePtr(node Node, v reflect.Value, start xml.StartElement) error {
+	if v.IsNil() {
+		return nil
+	}
+
+	if !isAllIdentifiers(node) {
+		return e.encodeValue(node, v.Elem(), start)
+	}
+
+	key := visitKey{ptr: v.Pointer(), typ: v.Type()}
+	if _, ok := e.visiting[key]; ok {
+		return ErrCyclicValue
+	}
+
+	if e.visiting == nil {
+		e.visiting = make(map[visitKey]struct{})
+	}
+
+	e.visiting[key] = struct{}{}
+	defer delete(e.visiting, key)
+
+	return e.encodeValue(node, v.Elem(), start)
+}
+
+//nolint:gocognit // one branch per kind of XML field
+func (e *xmlEncoder) encodeStruct(node Node, v reflect.Value, start xml.StartElement) error {
+	tp := xmlPlanFor(v.Type())
+
+	start = e.startFor(v.Type(), start)
+	start.Attr = nil
+
+	for _, fp := range tp.fields {
+		if fp.kind != xmlAttr {
+			continue
+		}
+
+		if _, ok := e.selectedField(node, v.Type(), fp); !ok {
+			continue
+		}
+
+		fv, exists := fieldByIndex(v, fp.index)
+		if !exists {
+			// promoted from a nil embedded pointer
+			continue
+		}
+
+		attr, ok, err := xmlAttrFor(fp, fv)
+		if err != nil {
+			return wrapFieldError(fp.name.Local, err)
+		}
+
+		if ok {
+			start.Attr = append(start.Attr, attr)
+		}
+	}
+
+	if err := e.enc.EncodeToken(start); err != nil {
+		return err
+	}
+
+	var open []string
+
+	for _, fp := range tp.fields {
+		fv, exists := fieldByIndex(v, fp.index)
+		if !exists {
+			continue
+		}
+
+		switch fp.kind {
+		case xmlAttr:
+			continue
+		case xmlCharData, xmlComment, xmlInnerXML:
+			// they can't be named, so they are written only when they can be selected through a wildcard
+			if fp.tag.restricted() || !e.opts.allowed(v.Type(), fp.fieldPlan()) {
+				continue
+			}
+
+			if err := e.switchParents(&open, nil); err != nil {
+				return err
+			}
+
+			if err := e.encodeText(fp.kind, fv); err != nil {
+				return err
+			}
+
+			continue
+		case xmlElement:
+		}
+
+		child, ok := e.selectedField(node, v.Type(), fp)
+		if !ok || (fp.omitEmpty && isEmptyValue(fv)) {
+			continue
+		}
+
+		if err := e.switchParents(&open, fp.parents); err != nil {
+			return err
+		}
+
+		if err := e.encodeValue(child, fv, xml.StartElement{Name: fp.name}); err != nil {
+			return wrapFieldError(strings.Join(append(slices.Clone(fp.parents), fp.name.Local), "."), err)
+		}
+	}
+
+	if err := e.switchParents(&open, nil); err != nil {
+		return err
+	}
+
+	return e.enc.EncodeToken(start.End())
+}
+
+// switchParents closes the open parent elements not shared with parents, and opens the missing ones.
+func (e *xmlEncoder) switchParents(open *[]string, parents []string) error {
+	shared := 0
+	for shared < len(*open) && shared < len(parents) && (*open)[shared] == parents[shared] {
+		shared++
+	}
+
+	for i := len(*open) - 1; i >= shared; i-- {
+		if err := e.enc.EncodeToken(xml.EndElement{Name: xml.Name{Local: (*open)[i]}}); err != nil {
+			return err
+		}
+	}
+
+	for _, p := range parents[shared:] {
+		if err := e.enc.EncodeToken(xml.StartElement{Name: xml.Name{Local: p}}); err != nil {
+			return err
+		}
+	}
+
+	*open = append((*open)[:shared], parents[shared:]...)
+
+	return nil
+}
+
+// encodeText writes the character data, comment or inner XML held by a field.
+func (e *xmlEncoder) encodeText(kind xmlFieldKind, v reflect.Value) error {
+	s, err := xmlText(v)
+	if err != nil {
+		return err
+	}
+
+	switch kind {
+	case xmlCharData:
+		return e.enc.EncodeToken(xml.CharData(s))
+	case xmlComment:
+		if s == "" {
+			return nil
+		}
+
+		return e.enc.EncodeToken(xml.Comment(s))
+	default:
+		// inner XML is written as it is
+		if err = e.enc.Flush(); err != nil {
+			return err
+		}
+
+		e.buf.WriteString(s)
+
+		return nil
+	}
+}
+
+// startFor returns the start element of a value of type t, the given one or the one set by the XMLName field
+// or the type name.
+func (e *xmlEncoder) startFor(t reflect.Type, start xml.StartElement) xml.StartElement {
+	if start.Name.Local != "" {
+		return start
+	}
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() == reflect.Struct {
+		if name := xmlPlanFor(t).name; name.Local != "" {
+			return xml.StartElement{Name: name}
+		}
+	}
+
+	return xml.StartElement{Name: xml.Name{Local: t.Name()}}
+}
+
+// selectedField returns the selection of the field of the struct type t in node, going through the children of
+// its parent elements, honoring its `fieldselect` tag like [options.selectedField].
+func (e *xmlEncoder) selectedField(node Node, t reflect.Type, fp xmlFieldPlan) (Node, bool) {
+	for _, parent := range fp.parents {
+		ident, ok := e.opts.selectField(node, parent)
+		if !ok {
+			// nothing inside the parent is named
+			node = Identifiers{}
+
+			break
+		}
+
+		node = childNode(ident)
+	}
+
+	return e.opts.selectedField(node, t, fp.fieldPlan())
+}
+
+// fieldPlan returns the field as it is seen by the field modes and roles, named after its local XML name.
+func (fp xmlFieldPlan) fieldPlan() fieldPlan {
+	return fieldPlan{name: fp.name.Local, tag: fp.tag}
+}
+
+// xmlAttrFor returns the attribute of a field, false when it is omitted.
+func xmlAttrFor(fp xmlFieldPlan, v reflect.Value) (xml.Attr, bool, error) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return xml.Attr{}, false, nil
+		}
+
+		v = v.Elem()
+	}
+
+	if fp.omitEmpty && isEmptyValue(v) {
+		return xml.Attr{}, false, nil
+	}
+
+	if m, ok := addressable(v).(xml.MarshalerAttr); ok {
+		attr, err := m.MarshalXMLAttr(fp.name)
+
+		return attr, attr.Name.Local != "", err
+	}
+
+	s, err := xmlText(v)
+	if err != nil {
+		return xml.Attr{}, false, err
+	}
+
+	return xml.Attr{Name: fp.name, Value: s}, true, nil
+}
+
+// xmlText formats a value as text, using its text representation if it has one.
+func xmlText(v reflect.Value) (string, error) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "", nil
+		}
+
+		v = v.Elem()
+	}
+
+	if tm, ok := addressable(v).(encoding.TextMarshaler); ok {
+		b, err := tm.MarshalText()
+
+		return string(b), err
+	}
+
+	if isBytes(v.Type()) {
+		return string(v.Bytes()), nil
+	}
+
+	return fmt.Sprint(v.Interface()), nil
+}
+
+// addressable returns the pointer to v when it is addressable, so that the pointer receiver marshalers are called,
+// or v itself.
+func addressable(v reflect.Value) any {
+	if v.CanAddr() {
+		return v.Addr().Interface()
+	}
+
+	return v.Interface()
+}
+
+// isXMLLeaf reports whether the values of t are encoded as a whole by encoding/xml.
+func isXMLLeaf(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr || t.Kind() == reflect.Interface {
+		return false
+	}
+
+	return isLeafType(t) || t.Implements(xmlMarshalerType) || reflect.PointerTo(t).Implements(xmlMarshalerType)
+}
+
+// xmlPlanFor returns the cached XML plan of the struct type t, computing it the first time.
+func xmlPlanFor(t reflect.Type) *xmlTypePlan {
+	if p, ok := xmlPlans.Load(t); ok {
+		//nolint:errcheck // it's always a *xmlTypePlan
+		return p.(*xmlTypePlan)
+	}
+
+	tp := &xmlTypePlan{}
+	tp.name, tp.fields = collectXMLFields(t, nil, make(map[reflect.Type]struct{}))
+
+	p, _ := xmlPlans.LoadOrStore(t, tp)
+
+	//nolint:errcheck // it's always a *xmlTypePlan
+	return p.(*xmlTypePlan)
+}
+
+// collectXMLFields returns the name set by the XMLName field and the exported fields of t, promoting the fields
+// of embedded structs, or pointers to structs, without tag like encoding/xml does, even when they are unexported.
+// visiting holds the types being collected, so a struct embedding itself is skipped.
+func collectXMLFields(t reflect.Type, index []int, visiting map[reflect.Type]struct{}) (xml.Name, []xmlFieldPlan) {
+	var (
+		name   xml.Name
+		fields []xmlFieldPlan
+	)
+
+	visiting[t] = struct{}{}
+	defer delete(visiting, t)
+
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("xml")
+		fieldIndex := append(slices.Clone(index), i)
+
+		if sf.Name == "XMLName" && sf.Type == xmlNameType {
+			tagName, _, _ := strings.Cut(tag, ",")
+			name = parseXMLName(tagName)
+
+			continue
+		}
+
+		if tag == "-" {
+			continue
+		}
+
+		et := sf.Type
+		if et.Kind() == reflect.Ptr {
+			et = et.Elem()
+		}
+
+		if sf.Anonymous && tag == "" && et.Kind() == reflect.Struct {
+			if _, embedsItself := visiting[et]; !embedsItself {
+				_, embedded := collectXMLFields(et, fieldIndex, visiting)
+				fields = append(fields, embedded...)
+			}
+
+			continue
+		}
+
+		if !sf.IsExported() {
+			continue
+		}
+
+		fields = append(fields, newXMLFieldPlan(sf, tag, fieldIndex))
+	}
+
+	return name, fields
+}
+
+func newXMLFieldPlan(sf reflect.StructField, tag string, index []int) xmlFieldPlan {
+	tagName, opts, _ := strings.Cut(tag, ",")
+
+	fp := xmlFieldPlan{index: index, kind: xmlElement, tag: parseTagOptions(sf)}
+
+	for opt := range strings.SplitSeq(opts, ",") {
+		switch opt {
+		case "attr":
+			fp.kind = xmlAttr
+		case "chardata":
+			fp.kind = xmlCharData
+		case "innerxml":
+			fp.kind = xmlInnerXML
+		case "comment":
+			fp.kind = xmlComment
+		case "omitempty":
+			fp.omitEmpty = true
+		}
+	}
+
+	if tagName == "" {
+		tagName = sf.Name
+	}
+
+	if parents := strings.Split(tagName, ">"); len(parents) > 1 {
+		tagName = parents[len(parents)-1]
+		fp.parents = parents[:len(parents)-1]
+	}
+
+	fp.name = parseXMLName(tagName)
+
+	return fp
+}
+
+// parseXMLName parses the `namespace name` form of the xml tags.
+func parseXMLName(s string) xml.Name {
+	if space, local, ok := strings.Cut(s, " "); ok {
+		return xml.Name{Space: space, Local: local}
+	}
+
+	return xml.Name{Local: s}
+}
@@ -0,0 +1,229 @@
+package gofieldselect
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"testing"
+	"time"
+)
+
+type (
+	xmlPrice struct {
+		Currency string  `xml:"currency,attr"`
+		Amount   float64 `xml:",chardata"`
+	}
+
+	xmlBook struct {
+		XMLName   xml.Name  `xml:"book"`
+		ID        int       `xml:"id,attr"`
+		Lang      string    `xml:"lang,attr,omitempty"`
+		Title     string    `xml:"title"`
+		Authors   []string  `xml:"authors>author"`
+		Publisher string    `xml:"info>publisher"`
+		Year      int       `xml:"info>year"`
+		Price     *xmlPrice `xml:"price"`
+		Published time.Time `xml:"published"`
+		Secret    string    `xml:"-"`
+		Note      string    `xml:",comment"`
+	}
+)
+
+func newXMLBook() xmlBook {
+	return xmlBook{
+		ID:        1,
+		Title:     "Go",
+		Authors:   []string{"Alan", "Brian"},
+		Publisher: "AW",
+		Year:      2015,
+		Price:     &xmlPrice{Currency: "EUR", Amount: 30.5},
+		Published: time.Date(2015, 10, 26, 0, 0, 0, 0, time.UTC),
+		Secret:    "secret",
+		Note:      "note",
+	}
+}
+
+func TestMarshalXMLAllFieldsMatchesEncodingXML(t *testing.T) {
+	t.Parallel()
+
+	b := newXMLBook()
+
+	expected, err := xml.Marshal(b)
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	got, err := MarshalXML(nil, b)
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	if !bytes.Equal(got, expected) {
+		t.Fatalf("expected %s; got %s", expected, got)
+	}
+}
+
+func TestMarshalXML(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		selection string
+		expected  string
+	}{
+		"attributes and elements": {
+			selection: "id,title",
+			expected:  `<book id="1"><title>Go</title><!--note--></book>`,
+		},
+		"parent paths": {
+			selection: "authors(author),info(year)",
+			expected:  `<book><authors><author>Alan</author><author>Brian</author></authors><info><year>2015</year></info><!--note--></book>`,
+		},
+		"nested attribute": {
+			selection: "price(currency)",
+			expected:  `<book><price currency="EUR">30.5</price><!--note--></book>`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := MarshalXML(parse(t, tc.selection), newXMLBook())
+			if err != nil {
+				t.Fatalf("error: %v", err)
+			}
+
+			if string(got) != tc.expected {
+				t.Fatalf("expected %s; got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestXMLEncoder(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	if err := NewXMLEncoder(&buf, parse(t, "title")).Encode([]xmlBook{{Title: "A"}, {Title: "B"}}); err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	expected := `<book><title>A</title></book><book><title>B</title></book>`
+	if buf.String() != expected {
+		t.Fatalf("expected %s; got %s", expected, buf.String())
+	}
+
+	err := NewXMLEncoder(&buf, parse(t, "published(day)")).Encode(newXMLBook())
+	if !errors.Is(err, ErrChildSelectionOnLeaf) {
+		t.Fatalf("expected ErrChildSelectionOnLeaf; got %v", err)
+	}
+}
+
+type (
+	XBase struct {
+		Code string `xml:"code"`
+	}
+
+	xmlRevision struct {
+		Rev int `xml:"rev,attr"`
+	}
+
+	// xmlDoc promotes the fields of embedded struct pointers, exported or not, like encoding/xml does.
+	xmlDoc struct {
+		XMLName xml.Name `xml:"doc"`
+		*XBase
+		*xmlRevision
+
+		Name string `xml:"name"`
+	}
+)
+
+func TestMarshalXMLEmbeddedPointers(t *testing.T) {
+	t.Parallel()
+
+	for _, doc := range []xmlDoc{
+		{XBase: &XBase{Code: "c"}, xmlRevision: &xmlRevision{Rev: 2}, Name: "n"},
+		{xmlRevision: &xmlRevision{Rev: 1}, Name: "n"},
+	} {
+		expected, err := xml.Marshal(doc)
+		if err != nil {
+			t.Fatalf("error: %v", err)
+		}
+
+		got, err := MarshalXML(nil, doc)
+		if err != nil {
+			t.Fatalf("error: %v", err)
+		}
+
+		if !bytes.Equal(got, expected) {
+			t.Fatalf("expected %s; got %s", expected, got)
+		}
+	}
+
+	got, err := MarshalXML(parse(t, "code"), xmlDoc{XBase: &XBase{Code: "c"}, Name: "n"})
+	if err != nil || string(got) != `<doc><code>c</code></doc>` {
+		t.Fatalf("expected only the promoted code, got %s, %v", got, err)
+	}
+
+	type selfEmbedding struct {
+		*selfEmbedding
+
+		Value int `xml:"value"`
+	}
+
+	if got, err = MarshalXML(nil, selfEmbedding{Value: 1}); err != nil ||
+		string(got) != `<selfEmbedding><value>1</value></selfEmbedding>` {
+		t.Fatalf("expected a struct embedding itself to be skipped, got %s, %v", got, err)
+	}
+}
+
+type (
+	// xmlCustom is written by its pointer receiver marshaler.
+	xmlCustom struct {
+		V string
+	}
+
+	// xmlLabel is written as an attribute and as text by its pointer receiver marshalers.
+	xmlLabel struct {
+		text string
+	}
+
+	xmlCustomHolder struct {
+		XMLName xml.Name  `xml:"holder"`
+		ID      xmlLabel  `xml:"id,attr"`
+		Label   xmlLabel  `xml:"label"`
+		P       xmlCustom `xml:"p"`
+	}
+)
+
+func (c *xmlCustom) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement("custom-"+c.V, start)
+}
+
+func (l *xmlLabel) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+	return xml.Attr{Name: name, Value: "attr-" + l.text}, nil
+}
+
+func (l *xmlLabel) MarshalText() ([]byte, error) {
+	return []byte("text-" + l.text), nil
+}
+
+func TestMarshalXMLPointerReceiverMarshalers(t *testing.T) {
+	t.Parallel()
+
+	holder := &xmlCustomHolder{ID: xmlLabel{text: "1"}, Label: xmlLabel{text: "l"}, P: xmlCustom{V: "v"}}
+
+	expected, err := xml.Marshal(holder)
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	got, err := MarshalXML(nil, holder)
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	if !bytes.Equal(got, expected) {
+		t.Fatalf("expected %s; got %s", expected, got)
+	}
+}